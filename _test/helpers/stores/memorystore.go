@@ -0,0 +1,217 @@
+package stores
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/jt0/gomer/data"
+	"github.com/jt0/gomer/data/dataerr"
+	"github.com/jt0/gomer/gomerr"
+)
+
+// MemoryStore is a data.Store that keeps copies of the exported fields of each persistable in memory. Persistables
+// must provide an 'Id() string' method (e.g. by being a resource.Instance). Queries return every stored persistable
+// of the queryable's first type name whose fields match the non-zero, same-named fields of the queryable.
+type MemoryStore map[string]reflect.Value
+
+func NewMemoryStore() MemoryStore {
+	return make(map[string]reflect.Value)
+}
+
+// Clear removes every stored persistable, e.g. so that a test starts with an empty store.
+func (m MemoryStore) Clear() {
+	for k := range m {
+		delete(m, k)
+	}
+}
+
+type identifiable interface {
+	Id() string
+}
+
+func (m MemoryStore) Create(p data.Persistable) gomerr.Gomerr {
+	k := key(p)
+	if _, exists := m[k]; exists {
+		return gomerr.Conflict(p, "Already exists")
+	}
+	data.SetVersion(p, 1)
+	m.put(k, p)
+	return nil
+}
+
+// Read copies the stored fields to p. If p is a data.AttributeSelector, only the selected fields are copied.
+func (m MemoryStore) Read(p data.Persistable) gomerr.Gomerr {
+	stored, ok := m[key(p)]
+	if !ok {
+		return dataerr.PersistableNotFound(p.TypeName(), key(p))
+	}
+	if selector, ok := p.(data.AttributeSelector); ok && len(selector.SelectedAttributes()) > 0 {
+		copySelected(reflect.ValueOf(p).Elem(), stored, selector.SelectedAttributes())
+	} else {
+		copyExported(reflect.ValueOf(p).Elem(), stored)
+	}
+	return nil
+}
+
+// ReadFull copies all of the stored fields to p (see data.FullReader).
+func (m MemoryStore) ReadFull(p data.Persistable) gomerr.Gomerr {
+	stored, ok := m[key(p)]
+	if !ok {
+		return dataerr.PersistableNotFound(p.TypeName(), key(p))
+	}
+	copyExported(reflect.ValueOf(p).Elem(), stored)
+	return nil
+}
+
+// Update applies the non-zero exported fields of update (if not nil) to p and then stores p. If p is data.Versioned,
+// the stored version must be the one p was read with.
+func (m MemoryStore) Update(p data.Persistable, update data.Persistable) gomerr.Gomerr {
+	k := key(p)
+	stored, ok := m[k]
+	if !ok {
+		return dataerr.PersistableNotFound(p.TypeName(), k)
+	}
+	_, version, versioned := data.Version(p)
+	if versioned && version != storedVersion(stored) {
+		return dataerr.VersionMismatch(p.TypeName(), version)
+	}
+	if update != nil && !reflect.ValueOf(update).IsNil() {
+		if explicitUpdate, ok := update.(data.ExplicitUpdate); ok && explicitUpdate.UpdateChanges() != nil {
+			copyChanged(reflect.ValueOf(p).Elem(), reflect.ValueOf(update).Elem(), explicitUpdate.UpdateChanges())
+		} else {
+			copyNonZero(reflect.ValueOf(p).Elem(), reflect.ValueOf(update).Elem())
+		}
+	}
+	if versioned {
+		data.SetVersion(p, version+1)
+	}
+	m.put(k, p)
+	return nil
+}
+
+func (m MemoryStore) Delete(p data.Persistable) gomerr.Gomerr {
+	k := key(p)
+	stored, ok := m[k]
+	if !ok {
+		return dataerr.PersistableNotFound(p.TypeName(), k)
+	}
+	if _, version, versioned := data.Version(p); versioned && version != 0 && version != storedVersion(stored) {
+		return dataerr.VersionMismatch(p.TypeName(), version)
+	}
+	delete(m, k)
+	return nil
+}
+
+// BatchMemoryStore is a MemoryStore that is also a data.BatchDeleter.
+type BatchMemoryStore struct {
+	MemoryStore
+}
+
+func NewBatchMemoryStore() BatchMemoryStore {
+	return BatchMemoryStore{MemoryStore: NewMemoryStore()}
+}
+
+// BatchDelete deletes each of the persistables. If any isn't found, none are deleted.
+func (m BatchMemoryStore) BatchDelete(ps []data.Persistable) gomerr.Gomerr {
+	for _, p := range ps {
+		if _, ok := m.MemoryStore[key(p)]; !ok {
+			return dataerr.PersistableNotFound(p.TypeName(), key(p))
+		}
+	}
+	for _, p := range ps {
+		delete(m.MemoryStore, key(p))
+	}
+	return nil
+}
+
+func (m MemoryStore) Query(q data.Queryable) gomerr.Gomerr {
+	typeName := q.TypeNames()[0]
+	qv := reflect.ValueOf(q).Elem()
+
+	var items []interface{}
+	for k, stored := range m {
+		if !strings.HasPrefix(k, typeName+"/") || !matches(qv, stored) {
+			continue
+		}
+		item := reflect.New(stored.Type())
+		copyExported(item.Elem(), stored)
+		items = append(items, item.Interface())
+	}
+
+	q.SetItems(items)
+	return nil
+}
+
+func (m MemoryStore) put(k string, p data.Persistable) {
+	pv := reflect.ValueOf(p).Elem()
+	stored := reflect.New(pv.Type()).Elem()
+	copyExported(stored, pv)
+	m[k] = stored
+}
+
+func storedVersion(stored reflect.Value) int64 {
+	_, version, _ := data.Version(stored.Addr().Interface().(data.Persistable))
+	return version
+}
+
+func key(p data.Persistable) string {
+	return p.TypeName() + "/" + p.(identifiable).Id()
+}
+
+func copyExported(to, from reflect.Value) {
+	for i := 0; i < to.NumField(); i++ {
+		tf := to.Field(i)
+		if !tf.CanSet() {
+			continue
+		}
+		if to.Type().Field(i).Anonymous && tf.Kind() == reflect.Struct {
+			copyExported(tf, from.Field(i))
+		} else {
+			tf.Set(from.Field(i))
+		}
+	}
+}
+
+func copyNonZero(to, from reflect.Value) {
+	for i := 0; i < to.NumField(); i++ {
+		tf := to.Field(i)
+		if !tf.CanSet() {
+			continue
+		}
+		if to.Type().Field(i).Anonymous && tf.Kind() == reflect.Struct {
+			copyNonZero(tf, from.Field(i))
+		} else if !from.Field(i).IsZero() {
+			tf.Set(from.Field(i))
+		}
+	}
+}
+
+func copySelected(to, from reflect.Value, fields []string) {
+	for _, name := range fields {
+		to.FieldByName(name).Set(from.FieldByName(name))
+	}
+}
+
+func copyChanged(to, from reflect.Value, changes *data.Changes) {
+	for name := range changes.Set {
+		to.FieldByName(name).Set(from.FieldByName(name))
+	}
+	for name := range changes.Removed {
+		tf := to.FieldByName(name)
+		tf.Set(reflect.Zero(tf.Type()))
+	}
+}
+
+func matches(qv, stored reflect.Value) bool {
+	for i := 0; i < qv.NumField(); i++ {
+		qf := qv.Field(i)
+		if !qf.CanInterface() || qf.Kind() == reflect.Struct || qf.IsZero() {
+			continue
+		}
+		sf := stored.FieldByName(qv.Type().Field(i).Name)
+		if sf.IsValid() && !reflect.DeepEqual(qf.Interface(), sf.Interface()) {
+			return false
+		}
+	}
+	return true
+}
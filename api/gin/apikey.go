@@ -0,0 +1,30 @@
+package gin
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/jt0/gomer/auth"
+	"github.com/jt0/gomer/auth/apikey"
+	"github.com/jt0/gomer/gomerr"
+)
+
+// ApiKeySubjectProvider returns a SubjectProvider that authenticates the "<keyId>.<secret>" value of the given header
+// (e.g. "X-Api-Key").
+func ApiKeySubjectProvider(authenticator *apikey.Authenticator, header string) SubjectProvider {
+	return func(c *gin.Context) (auth.Subject, gomerr.Gomerr) {
+		presented := c.GetHeader(header)
+		if presented == "" {
+			return nil, gomerr.MalformedValue(header, nil).WithReason("Missing API key")
+		}
+
+		return authenticator.Subject(presented)
+	}
+}
+
+// SignedRequestSubjectProvider returns a SubjectProvider that authenticates requests signed with an API key's signing
+// secret. See apikey.SignatureVerifier.
+func SignedRequestSubjectProvider(verifier *apikey.SignatureVerifier) SubjectProvider {
+	return func(c *gin.Context) (auth.Subject, gomerr.Gomerr) {
+		return verifier.Subject(c.Request)
+	}
+}
@@ -0,0 +1,40 @@
+package gin
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jt0/gomer/auth"
+	"github.com/jt0/gomer/auth/jwt"
+	"github.com/jt0/gomer/gomerr"
+)
+
+const ClaimsKey = "gomer-claims"
+
+// BearerTokenSubjectProvider returns a SubjectProvider that verifies the JWT in the request's "Authorization: Bearer"
+// header and returns a Subject with the principals mapped from its claims. The claims are available from the gin
+// context under ClaimsKey. A missing or malformed header results in a gomerr.BadValueError, as do invalid or expired
+// tokens (see jwt.Verifier's Verify).
+func BearerTokenSubjectProvider(verifier *jwt.Verifier) SubjectProvider {
+	return func(c *gin.Context) (auth.Subject, gomerr.Gomerr) {
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			return nil, gomerr.MalformedValue("Authorization", nil).WithReason("Missing bearer token")
+		}
+
+		scheme, token, found := strings.Cut(header, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") {
+			return nil, gomerr.MalformedValue("Authorization", nil).WithReason("Expected a bearer token")
+		}
+
+		subject, claims, ge := verifier.Subject(strings.TrimSpace(token))
+		if ge != nil {
+			return nil, ge
+		}
+
+		c.Set(ClaimsKey, claims)
+
+		return subject, nil
+	}
+}
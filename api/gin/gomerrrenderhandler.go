@@ -29,7 +29,12 @@ func GomerrRenderHandler(gomerrRenderer func(gomerr.Gomerr) http.StatusCoder) gi
 			rv = rv.Elem()
 		}
 
-		if rge := renderResult(rv, c, "", statusCoder.StatusCode()); rge != nil {
+		// Errors are rendered using the default content type if none of the supported ones are acceptable
+		rge := renderResult(rv, c, "", statusCoder.StatusCode(), http.AddAcceptToContext(c.Request))
+		if _, notAcceptable := rge.(*http.NotAcceptableError); notAcceptable {
+			rge = renderResult(rv, c, "", statusCoder.StatusCode())
+		}
+		if rge != nil {
 			panic(rge)
 		}
 	}
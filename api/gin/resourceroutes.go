@@ -1,6 +1,7 @@
 package gin
 
 import (
+	"fmt"
 	"net/http"
 	"reflect"
 	"strings"
@@ -8,8 +9,10 @@ import (
 	"github.com/gin-gonic/gin"
 
 	. "github.com/jt0/gomer/api/http"
+	"github.com/jt0/gomer/data"
 	"github.com/jt0/gomer/gomerr"
 	"github.com/jt0/gomer/resource"
+	"github.com/jt0/gomer/structs"
 )
 
 type HttpSpec struct {
@@ -71,7 +74,16 @@ func buildRoutes(r *gin.Engine, md resource.Metadata, parentPath string) {
 	}
 
 	for key, action := range md.Actions() {
-		op := key.(Op)
+		var op Op
+		var customOp CustomOp
+		switch k := key.(type) {
+		case Op:
+			op = k
+		case CustomOp:
+			op, customOp = k.Op, k
+		default:
+			panic("invalid action key; must be an http.Op or http.CustomOp")
+		}
 
 		relativePath, ok := path[op.ResourceType()]
 		if !ok {
@@ -83,16 +95,35 @@ func buildRoutes(r *gin.Engine, md resource.Metadata, parentPath string) {
 			successStatus = http.StatusOK
 		}
 
+		if customOp.Name != "" {
+			relativePath += "/" + customOp.Name
+			successStatus = customOp.SuccessStatusCode()
+			aliasCustomOp(customOp, action().Name(), instanceType)
+		}
+
 		r.Handle(op.Method(), relativePath, handler(md.ResourceType(action().AppliesToCategory()), action, successStatus))
 	}
 
-	if collectionType != nil { // Cannot have resources other than instances under a collection
-		for _, childMetadata := range md.Children() {
-			buildRoutes(r, childMetadata, path[resource.InstanceCategory])
-		}
+	// Children (including singletons) are always nested under the instance path, whether or not it has a collection
+	for _, childMetadata := range md.Children() {
+		buildRoutes(r, childMetadata, path[resource.InstanceCategory])
+	}
+}
+
+// customOpScopes maps the name of each CustomOp with a route to the name of its action. Since a CustomOp's name is
+// registered as a (global) scope alias, it can't be used by CustomOps whose actions differ, even for another resource.
+var customOpScopes = make(map[string]string)
+
+func aliasCustomOp(customOp CustomOp, actionName string, resourceType reflect.Type) {
+	if scope, ok := customOpScopes[customOp.Name]; ok && scope != actionName {
+		panic(fmt.Sprintf("%s on %s: custom op name '%s' is already used for %s", customOp, resourceType, customOp.Name, scope))
 	}
+
+	customOpScopes[customOp.Name] = actionName
+	structs.ScopeAlias(customOp.Name, actionName)
 }
 
+// variablePath appends a ':<Type>Id' segment whose value can be bound with a 'path.<Type>Id' directive.
 func variablePath(resourceType reflect.Type, path string) string {
 	return path + "/:" + typeName(resourceType) + "Id"
 }
@@ -108,26 +139,60 @@ func typeName(t reflect.Type) string {
 	return s[dotIndex+1:]
 }
 
+// handler performs the action and renders its result. An instance's response includes its ETag, and a GET or HEAD
+// request whose If-None-Match header matches it receives a 304 (Not Modified) response instead. Any files uploaded
+// with the request are deleted once the response has been written.
 func handler(resourceType reflect.Type, actionFunc func() resource.Action, successStatus int) func(c *gin.Context) {
 	return func(c *gin.Context) {
+		defer RemoveUploadedFiles(c.Request)
+
 		action := actionFunc()
-		if r, ge := BindFromRequest(c.Request, resourceType, Subject(c), action.Name()); ge != nil {
+		if r, ge := BindFromRequest(c.Request, resourceType, Subject(c), action.Name(), AddPathParamsToContext(pathParams(c))); ge != nil {
 			_ = c.Error(ge)
 		} else if r, ge = r.DoAction(action); ge != nil {
+			_ = c.Error(PreconditionFailedIfVersionChanged(c.Request, ge))
+		} else if notModified, ge := setETag(c, r, action); ge != nil {
 			_ = c.Error(ge)
-		} else if ge = renderResult(reflect.ValueOf(r).Elem(), c, action.Name(), successStatus); ge != nil {
+		} else if notModified {
+			c.Status(http.StatusNotModified)
+		} else if ge = renderResult(reflect.ValueOf(r).Elem(), c, action.Name(), successStatus, AddFieldSelectionToContext(c.Request, AddAcceptToContext(c.Request))); ge != nil {
 			_ = c.Error(ge)
 		}
 	}
 }
 
-func renderResult(result reflect.Value, c *gin.Context, scope string, statusCode int) gomerr.Gomerr {
-	bytes, ge := BindToResponse(result, c.Writer.Header(), scope, c.Request.Header.Get("Accept-Language"))
-	if ge != nil {
-		return ge
+// setETag adds the ETag header for an instance result (other than one that was deleted), and returns true if the
+// request is a GET or HEAD whose If-None-Match header matches it. An instance that was only partly read (see
+// resource.SelectAttributes) has no tag unless it's data.Versioned, since its content doesn't identify the stored one.
+func setETag(c *gin.Context, r resource.Resource, action resource.Action) (bool, gomerr.Gomerr) {
+	if action.AppliesToCategory() != resource.InstanceCategory || c.Request.Method == http.MethodDelete {
+		return false, nil
+	}
+	if selector, ok := r.(data.AttributeSelector); ok && len(selector.SelectedAttributes()) > 0 {
+		if _, versioned := r.(data.Versioned); !versioned {
+			return false, nil
+		}
 	}
 
-	c.Data(statusCode, c.Writer.Header().Get(ContentTypeHeader), bytes)
+	etag, ge := ETag(reflect.ValueOf(r).Elem())
+	if ge != nil || etag == "" {
+		return false, ge
+	}
+	c.Header(ETagHeader, etag)
+
+	return (c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead) && NotModified(c.Request, etag), nil
+}
+
+func pathParams(c *gin.Context) map[string]string {
+	params := make(map[string]string, len(c.Params))
+	for _, param := range c.Params {
+		params[param.Key] = param.Value
+	}
+
+	return params
+}
 
-	return nil
+func renderResult(result reflect.Value, c *gin.Context, scope string, statusCode int, tcs ...*structs.ToolContext) gomerr.Gomerr {
+	c.Status(statusCode)
+	return WriteResponse(c.Writer, result, c.Writer.Header(), scope, c.Request.Header.Get("Accept-Language"), tcs...)
 }
@@ -0,0 +1,246 @@
+package gin_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/jt0/gomer/_test/assert"
+	"github.com/jt0/gomer/_test/helpers/stores"
+	. "github.com/jt0/gomer/api/gin"
+	. "github.com/jt0/gomer/api/http"
+	"github.com/jt0/gomer/auth"
+	"github.com/jt0/gomer/data"
+	"github.com/jt0/gomer/gomerr"
+	"github.com/jt0/gomer/resource"
+)
+
+type Widget struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	WidgetId string `in:"path.+" id:"+" out:"+"`
+	Name     string
+	Approved bool `out:"+"`
+}
+
+type Widgets struct {
+	resource.BaseCollection `structs:"ignore"`
+	data.BaseQueryable      `structs:"ignore"`
+}
+
+func (Widgets) MaximumPageSize() int {
+	return 0
+}
+
+type Gizmo struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	GizmoId string `in:"path.+" id:"+"`
+}
+
+type Gizmos struct {
+	resource.BaseCollection `structs:"ignore"`
+	data.BaseQueryable      `structs:"ignore"`
+}
+
+func (Gizmos) MaximumPageSize() int {
+	return 0
+}
+
+// approveAction marks a widget as approved without storing it
+type approveAction struct {
+	resource.NoOpAction
+}
+
+func (approveAction) Name() string {
+	return "Approve"
+}
+
+func (approveAction) AppliesToCategory() resource.Category {
+	return resource.InstanceCategory
+}
+
+func (approveAction) FieldAccessPermissions() auth.AccessPermissions {
+	return auth.UpdatePermission
+}
+
+func (approveAction) Do(r resource.Resource) gomerr.Gomerr {
+	r.(*Widget).Approved = true
+	return nil
+}
+
+type rejectAction struct {
+	approveAction
+}
+
+func (rejectAction) Name() string {
+	return "Reject"
+}
+
+type Attachment struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	Content *File `in:"file.content"`
+}
+
+// uploadAction records the location of the attachment's uploaded content
+type uploadAction struct {
+	resource.NoOpAction
+}
+
+var uploadedTo string
+
+func (uploadAction) Name() string {
+	return "Upload"
+}
+
+func (uploadAction) AppliesToCategory() resource.Category {
+	return resource.InstanceCategory
+}
+
+func (uploadAction) FieldAccessPermissions() auth.AccessPermissions {
+	return auth.CreatePermission
+}
+
+func (uploadAction) Do(r resource.Resource) gomerr.Gomerr {
+	file, ge := r.(*Attachment).Content.Open()
+	if ge != nil {
+		return ge
+	}
+	defer file.Close()
+
+	if f, ok := file.(*os.File); ok {
+		uploadedTo = f.Name()
+	}
+	return nil
+}
+
+var (
+	approve = NewCustomOp(Post, resource.InstanceCategory, "approve")
+	archive = NewCustomOp(Delete_, resource.InstanceCategory, "archive")
+	reorder = NewCustomOp(Post, resource.InstanceCategory, "reorder").WithSuccessStatusCode(http.StatusAccepted)
+
+	store = stores.NewMemoryStore()
+)
+
+func newEngine(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	store.Clear()
+	actions := map[interface{}]func() resource.Action{
+		PostCollection: resource.CreateAction,
+		GetInstance:    resource.ReadAction,
+		approve:        func() resource.Action { return approveAction{} },
+		archive:        func() resource.Action { return approveAction{} },
+		reorder:        func() resource.Action { return approveAction{} },
+	}
+	md, ge := resource.Register(&Widget{}, &Widgets{}, actions, store, nil)
+	assert.Success(t, ge)
+
+	engine := gin.New()
+	engine.Use(SubjectHandler(func(*gin.Context) (auth.Subject, gomerr.Gomerr) {
+		return auth.NewSubject(auth.ReadWriteAllFields), nil
+	}))
+	BuildRoutes(engine, md)
+
+	return engine
+}
+
+func serve(engine *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func TestCustomOpRoutes(t *testing.T) {
+	engine := newEngine(t)
+
+	created := serve(engine, http.MethodPost, "/widgets", `{"WidgetId":"w1","Name":"Sprocket"}`)
+	assert.Equals(t, http.StatusCreated, created.Code)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		expectedStatus int
+	}{
+		{"PostDefaultsToOK", http.MethodPost, "/widgets/w1/approve", http.StatusOK},
+		{"DeleteDefaultsToNoContent", http.MethodDelete, "/widgets/w1/archive", http.StatusNoContent},
+		{"ExplicitSuccessStatusCode", http.MethodPost, "/widgets/w1/reorder", http.StatusAccepted},
+		{"UnknownCustomOp", http.MethodPost, "/widgets/w1/reject", http.StatusNotFound},
+		{"WrongMethod", http.MethodGet, "/widgets/w1/approve", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equals(t, tt.expectedStatus, serve(engine, tt.method, tt.path, "").Code)
+		})
+	}
+
+	approved := serve(engine, http.MethodPost, "/widgets/w1/approve", "")
+	var widget map[string]interface{}
+	assert.Success(t, json.Unmarshal(approved.Body.Bytes(), &widget))
+	assert.Equals(t, "w1", widget["WidgetId"])
+	assert.Equals(t, true, widget["Approved"])
+}
+
+func TestConflictingCustomOpNamesPanic(t *testing.T) {
+	engine := newEngine(t)
+
+	actions := map[interface{}]func() resource.Action{
+		approve: func() resource.Action { return rejectAction{} },
+	}
+	md, ge := resource.Register(&Gizmo{}, &Gizmos{}, actions, stores.PanicStore, nil)
+	assert.Success(t, ge)
+
+	defer func() {
+		r := recover()
+		assert.Assert(t, r != nil, "Expected a custom op name used by another action to be rejected")
+		assert.Assert(t, strings.Contains(r.(string), "Gizmo"), "Expected the panic to identify the resource: %v", r)
+	}()
+	BuildRoutes(engine, md)
+}
+
+func TestUploadedFilesAreRemoved(t *testing.T) {
+	config := NewBindFromRequestConfiguration()
+	config.MaxMultipartMemory = 1 // stores uploaded files in temporary files
+	SetBindFromRequestConfiguration(config)
+	defer SetBindFromRequestConfiguration(NewBindFromRequestConfiguration())
+
+	actions := map[interface{}]func() resource.Action{PostInstance: func() resource.Action { return uploadAction{} }}
+	md, ge := resource.Register(&Attachment{}, nil, actions, stores.PanicStore, nil)
+	assert.Success(t, ge)
+
+	engine := newEngine(t)
+	BuildRoutes(engine, md)
+
+	var content bytes.Buffer
+	writer := multipart.NewWriter(&content)
+	part, err := writer.CreateFormFile("content", "notes.txt")
+	assert.Success(t, err)
+	_, err = part.Write([]byte("uploaded content"))
+	assert.Success(t, err)
+	assert.Success(t, writer.Close())
+
+	uploadedTo = ""
+	request := httptest.NewRequest(http.MethodPost, "/attachment", &content)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, request)
+	assert.Equals(t, http.StatusCreated, recorder.Code)
+
+	assert.Assert(t, uploadedTo != "", "Expected the upload to be stored in a temporary file")
+	_, err = os.Stat(uploadedTo)
+	assert.Assert(t, os.IsNotExist(err), "Expected the temporary file to be removed")
+}
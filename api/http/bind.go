@@ -5,6 +5,8 @@ type BindDirectiveConfiguration struct {
 	PathBindingPrefix       string
 	HeaderBindingPrefix     string
 	QueryParamBindingPrefix string
+	FormBindingPrefix       string
+	FileBindingPrefix       string
 	PayloadBindingPrefix    string
 
 	// Default values for unqualified directives
@@ -28,6 +30,8 @@ func NewBindDirectiveConfiguration() BindDirectiveConfiguration {
 		PathBindingPrefix:         DefaultPathBindingPrefix,
 		HeaderBindingPrefix:       DefaultHeaderBindingPrefix,
 		QueryParamBindingPrefix:   DefaultQueryParamBindingPrefix,
+		FormBindingPrefix:         DefaultFormBindingPrefix,
+		FileBindingPrefix:         DefaultFileBindingPrefix,
 		PayloadBindingPrefix:      DefaultPayloadBindingPrefix,
 		SkipField:                 DefaultSkipFieldDirective,
 		IncludeField:              DefaultBindToFieldNameDirective,
@@ -54,10 +58,13 @@ const (
 )
 
 const (
-	DefaultContentType               = "application/json"
+	DefaultContentType               = JsonContentType
+	DefaultMaxMultipartMemory        = 32 << 20 // 32 MB
 	DefaultPathBindingPrefix         = "path."
 	DefaultHeaderBindingPrefix       = "header."
 	DefaultQueryParamBindingPrefix   = "query."
+	DefaultFormBindingPrefix         = "form."
+	DefaultFileBindingPrefix         = "file."
 	DefaultPayloadBindingPrefix      = ""
 	DefaultSkipFieldDirective        = "-"
 	DefaultBindToFieldNameDirective  = "+"
@@ -68,14 +75,26 @@ const (
 	DefaultEmptyValueHandlingDefault = OmitEmpty
 
 	ContentTypeHeader = "Content-Type"
-	AcceptsHeader     = "Accepts"
+	AcceptHeader      = "Accept"
+	ETagHeader        = "ETag"
+	IfMatchHeader     = "If-Match"
+	IfNoneMatchHeader = "If-None-Match"
 
+	// Deprecated: AcceptsHeader isn't a standard header. Use AcceptHeader.
+	AcceptsHeader = "Accepts"
+
+	PathParamsKey     = "$_path_params"
+	AcceptKey         = "$_accept"
 	AcceptLanguageKey = "$_accept_language"
 
+	FieldsQueryParam = "fields"
+
 	pathPartsKey   = "$_path_parts"
 	queryParamsKey = "$_query_params"
 	headersKey     = "$_headers"
-	bodyBytesKey   = "$_body_bytes"
+	bodyKey        = "$_body"
+	formValuesKey  = "$_form_values"
+	formFilesKey   = "$_form_files"
 
 	// toolsWithContextKey = "$_tools_with_context"
 )
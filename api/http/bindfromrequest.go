@@ -1,20 +1,23 @@
 package http
 
 import (
-	"encoding/json"
-	"io/ioutil"
+	"errors"
+	"io"
 	"net/http"
 	"net/textproto"
 	"net/url"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/jt0/gomer/auth"
 	bind2 "github.com/jt0/gomer/bind"
 	"github.com/jt0/gomer/constraint"
+	"github.com/jt0/gomer/data"
 	"github.com/jt0/gomer/flect"
 	"github.com/jt0/gomer/gomerr"
+	"github.com/jt0/gomer/limit"
 	"github.com/jt0/gomer/resource"
 	"github.com/jt0/gomer/structs"
 )
@@ -25,9 +28,20 @@ type BindFromRequestConfiguration struct {
 	BindConfiguration bind2.Configuration
 	BindDirectiveConfiguration
 
+	// If true, query parameter names are matched regardless of case, with the values of an exact match bound first.
+	// Header names are always matched regardless of case.
+	CaseInsensitiveQueryParams bool
+
+	// The maximum number of bytes that can be read from a request's body, or 0 if there's no limit. A streamed body
+	// (see requestExtension) returns an *http.MaxBytesError if more is read.
+	MaxBodySize int64
+
+	// The number of bytes of a multipart/form-data request's files kept in memory. The rest are stored in temporary
+	// files (see File).
+	MaxMultipartMemory int64
+
 	defaultContentType               string
 	perContentTypeUnmarshalFunctions map[string]Unmarshal
-	defaultUnmarshalFunction         Unmarshal
 }
 
 // Unmarshal defines a function that processes the input and stores the result in the value pointed to by ptrToTarget.
@@ -38,12 +52,27 @@ func NewBindFromRequestConfiguration() BindFromRequestConfiguration {
 	return BindFromRequestConfiguration{
 		BindConfiguration:                bind2.NewConfiguration(),
 		BindDirectiveConfiguration:       NewBindDirectiveConfiguration(),
+		MaxMultipartMemory:               DefaultMaxMultipartMemory,
 		defaultContentType:               DefaultContentType,
-		perContentTypeUnmarshalFunctions: make(map[string]Unmarshal),
-		defaultUnmarshalFunction:         json.Unmarshal,
+		perContentTypeUnmarshalFunctions: builtInUnmarshalFunctions(),
+	}
+}
+
+// AddUnmarshalFunction sets the function used to unmarshal request bodies of the content type. A nil function removes
+// support for the content type.
+func (c *BindFromRequestConfiguration) AddUnmarshalFunction(contentType string, unmarshal Unmarshal) {
+	if unmarshal == nil {
+		delete(c.perContentTypeUnmarshalFunctions, normalizedMediaType(contentType))
+	} else {
+		c.perContentTypeUnmarshalFunctions[normalizedMediaType(contentType)] = unmarshal
 	}
 }
 
+// RegisterUnmarshalFunction adds the unmarshal function to the current BindFromRequestConfiguration.
+func RegisterUnmarshalFunction(contentType string, unmarshal Unmarshal) {
+	requestConfig.AddUnmarshalFunction(contentType, unmarshal)
+}
+
 var (
 	DefaultBindFromRequestTool *structs.Tool
 	requestConfig              BindFromRequestConfiguration
@@ -63,38 +92,105 @@ func SetBindFromRequestConfiguration(requestConfiguration BindFromRequestConfigu
 	return DefaultBindFromRequestTool
 }
 
-func BindFromRequest(request *http.Request, resourceType reflect.Type, subject auth.Subject, scope string) (resource.Resource, gomerr.Gomerr) {
+// BindFromRequest creates a new resource of the given type and binds the request's data to it. An optional ToolContext
+// can be provided with additional request data, e.g. the named path parameters matched by a router (see
+// AddPathParamsToContext).
+//
+// A request with a patch content type (MergePatchContentType or JsonPatchContentType) binds an explicit update (see
+// resource.SetUpdateChanges): each top-level field in the patch is changed, even to its zero value, a merge patch's null
+// (or a JSON Patch 'remove') removes the field, and the others are left unchanged. A JSON Patch that adds or replaces
+// a value with null sets the field to its zero value instead, and one that replaces a field fails unless the stored
+// instance has a (non-zero) value for it. A nested object replaces the field's whole value rather than being merged
+// into it.
+//
+// If a PUT, PATCH, or DELETE request for an instance has an If-Match header, the action is only performed if the header
+// matches the stored instance's entity tag (see ETag). Otherwise, it fails with a PreconditionFailedError.
+//
+// A request that selects response fields that the resource type doesn't have (see AddFieldSelectionToContext) is
+// rejected with a gomerr.BadValueError. A GET or HEAD request for an instance reads only the selected fields from the
+// data store (see resource.SelectAttributes).
+func BindFromRequest(request *http.Request, resourceType reflect.Type, subject auth.Subject, scope string, tcs ...*structs.ToolContext) (resource.Resource, gomerr.Gomerr) {
 	r, ge := resource.New(resourceType, subject)
 	if ge != nil {
 		return nil, ge
 	}
 
-	tc := structs.ToolContextWithScope(scope).
+	// Unknown fields in the response's selection are rejected before the request is acted upon
+	selectedFields, ge := verifyFieldSelection(request, resourceType.Elem(), scope)
+	if ge != nil {
+		return nil, ge
+	}
+
+	// A read of an instance only needs the selected fields from the data store. A type that doesn't embed
+	// resource.BaseInstance is read in full.
+	if instance, ok := r.(resource.Instance); ok && len(selectedFields) > 0 && (request.Method == http.MethodGet || request.Method == http.MethodHead) {
+		_ = resource.SelectAttributes(instance, selectedFields)
+	}
+
+	if instance, ok := r.(resource.Instance); ok && conditionalMethods[request.Method] {
+		if ifMatch := request.Header.Get(IfMatchHeader); ifMatch != "" {
+			if ge = resource.SetPrecondition(instance, ifMatchPrecondition(ifMatch)); ge != nil {
+				return nil, ge
+			}
+		}
+	}
+
+	tc := structs.EnsureContext(tcs...).PutScope(scope).
 		Put(pathPartsKey, strings.Split(strings.Trim(request.URL.Path, "/"), "/")). // remove any leading or trailing slashes
 		Put(queryParamsKey, request.URL.Query()).
 		Put(headersKey, request.Header)
 
-	bodyBytes, err := ioutil.ReadAll(request.Body) // TODO:p3 Support streaming rather than using []byte
+	// Ensures the 'body' binding (if any) is known before the body is read
+	if ge = structs.Preprocess(r, DefaultBindFromRequestTool); ge != nil {
+		return nil, ge
+	}
+
+	body := request.Body
+	if body == nil {
+		body = http.NoBody
+	}
+	if requestConfig.MaxBodySize > 0 {
+		body = http.MaxBytesReader(nil, body, requestConfig.MaxBodySize)
+	}
+
+	if contentType := normalizedMediaType(request.Header.Get(ContentTypeHeader)); hasFormBinding[resourceType.Elem().String()] &&
+		(contentType == FormContentType || contentType == MultipartFormContentType) {
+		request.Body = body
+		if ge = bindForm(request, contentType, tc); ge != nil {
+			return nil, ge
+		}
+		return r, applyRequestTools(r, tc)
+	}
+
+	if streamsInBody[resourceType.Elem().String()] {
+		return r, applyRequestTools(r, tc.Put(bodyKey, body))
+	}
+
+	bodyBytes, err := io.ReadAll(body)
 	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			return nil, limit.Exceeded("BindFromRequest", "body", limit.DataSize(requestConfig.MaxBodySize), limit.Unknown, limit.Unknown).Wrap(err)
+		}
 		return nil, gomerr.Internal("Failed to read request body content").Wrap(err)
 	}
 
 	if hasInBodyBinding[resourceType.Elem().String()] {
-		tc.Put(bodyBytesKey, bodyBytes)
+		tc.Put(bodyKey, bodyBytes)
 	} else {
 		unmarshaled := make(map[string]interface{})
 
 		if len(bodyBytes) > 0 {
 			// based on content type, and the absence of any "body" attributes use the proper unmarshaler to put the
-			// data into the new resource
+			// data into the new resource. A request without a content type is treated as the default one.
 			// TODO:p3 Allow applications to provide alternative means to choose an unmarshaler
-			contentType := request.Header.Get(ContentTypeHeader)
+			contentType := normalizedMediaType(request.Header.Get(ContentTypeHeader))
+			if contentType == "" {
+				contentType = requestConfig.defaultContentType
+			}
 			unmarshal, ok := requestConfig.perContentTypeUnmarshalFunctions[contentType]
 			if !ok {
-				if requestConfig.defaultUnmarshalFunction == nil {
-					return nil, gomerr.Unmarshal("Unsupported content-type", contentType, nil)
-				}
-				unmarshal = requestConfig.defaultUnmarshalFunction
+				return nil, UnsupportedMediaType(contentType, supportedContentTypes(requestConfig.perContentTypeUnmarshalFunctions))
 			}
 
 			if err = unmarshal(bodyBytes, &unmarshaled); err != nil {
@@ -105,30 +201,123 @@ func BindFromRequest(request *http.Request, resourceType reflect.Type, subject a
 		tc.Put(bind2.InKey, unmarshaled)
 	}
 
-	return r, structs.ApplyTools(r, tc, DefaultBindFromRequestTool, constraint.DefaultValidationTool)
+	if contentType := normalizedMediaType(request.Header.Get(ContentTypeHeader)); contentType == MergePatchContentType || contentType == JsonPatchContentType {
+		return r, applyPatchRequestTools(r, tc, request)
+	}
+
+	return r, applyRequestTools(r, tc)
+}
+
+// applyRequestTools binds the request's data to the resource and validates it. If the request binding is strict for
+// the scope (see bind.StrictInput), payload values that aren't bound to a field are also rejected.
+func applyRequestTools(r resource.Resource, tc *structs.ToolContext) gomerr.Gomerr {
+	ge := structs.ApplyTools(r, tc, DefaultBindFromRequestTool, constraint.DefaultValidationTool)
+	return gomerr.Batch(ge, bind2.VerifyInput(DefaultBindFromRequestTool, tc))
+}
+
+// applyPatchRequestTools is like applyRequestTools, but also tracks the fields the patch changes so they're updated
+// explicitly.
+func applyPatchRequestTools(r resource.Resource, tc *structs.ToolContext, request *http.Request) gomerr.Gomerr {
+	if ge := applyRequestTools(r, bind2.AddChangeTrackingToContext(tc)); ge != nil {
+		return ge
+	}
+
+	set, removed := bind2.TrackedChanges(tc)
+	if ge := resource.SetUpdateChanges(r, &data.Changes{Set: set, Removed: removed}); ge != nil {
+		return ge
+	}
+
+	return requireReplacedFields(r, tc, request)
+}
+
+// requireReplacedFields has the update fail unless each field that the patch replaces (see bind.Replacement) has a
+// (non-zero) value in the stored instance. A replaced member that isn't bound to a field is rejected outright. Since an
+// instance has one precondition, the If-Match header's (if any) is checked first by the same one.
+func requireReplacedFields(r resource.Resource, tc *structs.ToolContext, request *http.Request) gomerr.Gomerr {
+	replaced := bind2.TrackedReplacements(tc)
+	patch, _ := tc.Get(bind2.InKey).(map[string]interface{})
+	for name, value := range patch {
+		if _, replacement := value.(bind2.Replacement); replacement && replaced[name] == "" {
+			return gomerr.InvalidValue("path", "/"+name, "existing member")
+		}
+	}
+	if len(replaced) == 0 {
+		return nil
+	}
+
+	instance, ok := r.(resource.Instance)
+	if !ok {
+		return gomerr.Unprocessable("Only an instance's members can be replaced", r)
+	}
+
+	ifMatch := request.Header.Get(IfMatchHeader)
+	return resource.SetPrecondition(instance, func(current resource.Instance) gomerr.Gomerr {
+		if ifMatch != "" {
+			if ge := ifMatchPrecondition(ifMatch)(current); ge != nil {
+				return ge
+			}
+		}
+
+		cv := reflect.ValueOf(current).Elem()
+		for name, field := range replaced {
+			if cv.FieldByName(field).IsZero() {
+				return gomerr.InvalidValue("path", "/"+name, "existing member")
+			}
+		}
+		return nil
+	})
 }
 
 // requestExtension
 //
 // path.<n>      -> <n>th path part from the request's URL
+// path.<name>   -> Path parameter with name <name> (see AddPathParamsToContext)
 // query.<name>  -> Query parameter with name <name>
 // header.<name> -> Header with name <name>
-// body          -> Body of the request
+// form.<name>   -> Form field with name <name> (form-urlencoded or multipart/form-data requests)
+// file.<name>   -> Uploaded file with name <name> (multipart/form-data requests; see File)
+// body          -> Body of the request ([]byte, io.Reader, or io.ReadCloser)
+//
+// A body bound to an io.Reader or io.ReadCloser is streamed rather than read into memory, so the application is
+// responsible for reading (and closing) it.
 type requestExtension struct{}
 
 func (requestExtension) Applier(structType reflect.Type, structField reflect.StructField, directive string, _ string) (structs.Applier, gomerr.Gomerr) {
 	if strings.HasPrefix(directive, requestConfig.PathBindingPrefix) {
-		index, err := strconv.Atoi(directive[len(requestConfig.PathBindingPrefix):])
-		if err != nil {
-			return nil, gomerr.Configuration("Expected numeric index value for path binding, received: " + directive)
+		pathParamName := directive[len(requestConfig.PathBindingPrefix):]
+		if pathParamName == requestConfig.IncludeField {
+			pathParamName = structField.Name
+		} else if pathParamName == "" {
+			return nil, gomerr.Configuration("Expected an index or name for path binding, received: " + directive)
+		}
+
+		if index, err := strconv.Atoi(pathParamName); err == nil {
+			return bindPathApplier{index}, nil
 		}
-		return bindPathApplier{index}, nil
+		return bindPathParamApplier{pathParamName}, nil
 	} else if strings.HasPrefix(directive, requestConfig.QueryParamBindingPrefix) {
 		queryParamName := directive[len(requestConfig.QueryParamBindingPrefix):]
 		if queryParamName == requestConfig.IncludeField {
 			queryParamName = structField.Name
 		}
 		return bindQueryParamApplier{queryParamName}, nil
+	} else if strings.HasPrefix(directive, requestConfig.FormBindingPrefix) {
+		formFieldName := directive[len(requestConfig.FormBindingPrefix):]
+		if formFieldName == requestConfig.IncludeField {
+			formFieldName = structField.Name
+		}
+		hasFormBinding[structType.String()] = true
+		return bindFormApplier{formFieldName}, nil
+	} else if strings.HasPrefix(directive, requestConfig.FileBindingPrefix) {
+		if ft := structField.Type; ft != fileType && ft != filePtrType && ft != filePtrsType {
+			return nil, gomerr.Configuration("File field must be of type http.File, *http.File, or []*http.File, not: " + ft.String())
+		}
+		fileName := directive[len(requestConfig.FileBindingPrefix):]
+		if fileName == requestConfig.IncludeField {
+			fileName = structField.Name
+		}
+		hasFormBinding[structType.String()] = true
+		return bindFileApplier{fileName}, nil
 	} else if strings.HasPrefix(directive, requestConfig.HeaderBindingPrefix) {
 		headerName := directive[len(requestConfig.HeaderBindingPrefix):]
 		if headerName == requestConfig.IncludeField {
@@ -136,8 +325,10 @@ func (requestExtension) Applier(structType reflect.Type, structField reflect.Str
 		}
 		return bindRequestHeaderApplier{headerName}, nil
 	} else if directive == requestConfig.BindBody {
-		if structField.Type != byteSliceType {
-			return nil, gomerr.Configuration("Body field must be of type []byte, not: " + structField.Type.String())
+		if structField.Type.Kind() == reflect.Interface && readCloserType.AssignableTo(structField.Type) && structField.Type.NumMethod() > 0 {
+			streamsInBody[structType.String()] = true
+		} else if structField.Type != byteSliceType {
+			return nil, gomerr.Configuration("Body field must be of type []byte, io.Reader, or io.ReadCloser, not: " + structField.Type.String())
 		}
 		hasInBodyBinding[structType.String()] = true
 		return bodyInApplier{}, nil
@@ -152,7 +343,10 @@ func (requestExtension) Type() string {
 	return bindToResponseToolType
 }
 
-var hasInBodyBinding = make(map[string]bool)
+var (
+	hasInBodyBinding = make(map[string]bool)
+	streamsInBody    = make(map[string]bool) // the body is bound to an io.Reader or io.ReadCloser rather than []byte
+)
 
 type bindPathApplier struct {
 	index int
@@ -171,46 +365,162 @@ func (b bindPathApplier) Apply(_ reflect.Value, fv reflect.Value, tc *structs.To
 	return nil
 }
 
+type bindPathParamApplier struct {
+	name string
+}
+
+func (b bindPathParamApplier) Apply(_ reflect.Value, fv reflect.Value, tc *structs.ToolContext) gomerr.Gomerr {
+	value, hasValue := tc.Get(PathParamsKey).(map[string]string)[b.name]
+	if !hasValue {
+		return nil
+	}
+
+	if ge := flect.SetValue(fv, value); ge != nil {
+		return ge.AddAttributes("PathParameter", b.name)
+	}
+
+	return nil
+}
+
 type bindQueryParamApplier struct {
 	name string
 }
 
+// Apply binds the query parameter's value. A slice or array field is bound to all the parameter's values (repeated
+// and/or comma-separated), while a map or struct field is bound using deep-object style parameters (e.g.
+// 'filter[status]=open' for a 'query.filter' directive).
 func (b bindQueryParamApplier) Apply(_ reflect.Value, fv reflect.Value, tc *structs.ToolContext) gomerr.Gomerr {
-	// TODO:p3 support case-insensitive (or canonical) param names
-	values, hasValues := tc.Get(queryParamsKey).(url.Values)[b.name]
-	if !hasValues {
+	queryParams := tc.Get(queryParamsKey).(url.Values)
+
+	if isDeepObject(fv.Type()) {
+		return bindDeepObject(fv, queryParams, b.name)
+	}
+
+	values := queryParamValues(queryParams, b.name)
+	if len(values) == 0 {
 		return nil
 	}
 
-	if ge := flect.SetValue(fv, values[0]); ge != nil {
+	if ge := setValues(fv, values); ge != nil {
 		return ge.AddAttributes("Parameter", b.name)
 	}
 
 	return nil
 }
 
+// queryParamValues returns the values of the named query parameter. If parameter names are case-insensitive, the
+// values of an exact match come first followed by those of any other matches ordered by name, so the first value
+// bound to a non-slice field doesn't depend on map iteration order.
+func queryParamValues(queryParams url.Values, name string) []string {
+	if !requestConfig.CaseInsensitiveQueryParams {
+		return queryParams[name]
+	}
+
+	var others []string
+	for param := range queryParams {
+		if param != name && strings.EqualFold(param, name) {
+			others = append(others, param)
+		}
+	}
+	if len(others) == 0 {
+		return queryParams[name]
+	}
+	sort.Strings(others)
+
+	values := append([]string{}, queryParams[name]...)
+	for _, param := range others {
+		values = append(values, queryParams[param]...)
+	}
+	return values
+}
+
+func queryParamNameMatches(name, target string) bool {
+	return name == target || requestConfig.CaseInsensitiveQueryParams && strings.EqualFold(name, target)
+}
+
+func isDeepObject(t reflect.Type) bool {
+	return t.Kind() == reflect.Map && t.Key().Kind() == reflect.String || t.Kind() == reflect.Struct && !flect.ConvertsString(t)
+}
+
+func bindDeepObject(fv reflect.Value, queryParams url.Values, name string) gomerr.Gomerr {
+	prefix := name + "["
+	for param, values := range queryParams {
+		if len(param) <= len(prefix) || param[len(param)-1] != ']' || !queryParamNameMatches(param[:len(prefix)], prefix) {
+			continue
+		}
+		key := param[len(prefix) : len(param)-1]
+
+		var ge gomerr.Gomerr
+		if fv.Kind() == reflect.Map {
+			if fv.IsNil() {
+				fv.Set(reflect.MakeMap(fv.Type()))
+			}
+			ev := reflect.New(fv.Type().Elem()).Elem()
+			if ge = setValues(ev, values); ge == nil {
+				fv.SetMapIndex(reflect.ValueOf(key).Convert(fv.Type().Key()), ev)
+			}
+		} else if sf, ok := fv.Type().FieldByNameFunc(func(fieldName string) bool { return strings.EqualFold(fieldName, key) }); ok && sf.PkgPath == "" {
+			ge = setValues(fv.FieldByIndex(sf.Index), values)
+		}
+
+		if ge != nil {
+			return ge.AddAttributes("Parameter", param)
+		}
+	}
+
+	return nil
+}
+
 type bindRequestHeaderApplier struct {
 	name string
 }
 
+// Apply binds the header's value. As with query parameters, a slice or array field is bound to all the header's
+// values.
 func (b bindRequestHeaderApplier) Apply(_ reflect.Value, fv reflect.Value, tc *structs.ToolContext) gomerr.Gomerr {
 	values, hasValues := tc.Get(headersKey).(http.Header)[textproto.CanonicalMIMEHeaderKey(b.name)]
 	if !hasValues {
 		return nil
 	}
 
-	if ge := flect.SetValue(fv, values[0]); ge != nil {
+	if ge := setValues(fv, values); ge != nil {
 		return ge.AddAttributes("Header", b.name)
 	}
 
 	return nil
 }
 
+// setValues sets a slice or array (other than []byte or one converted from a string, e.g. net.IP) to the
+// comma-separated parts of each of the values, and any other type to the first value.
+func setValues(fv reflect.Value, values []string) gomerr.Gomerr {
+	if (fv.Kind() == reflect.Slice || fv.Kind() == reflect.Array) && fv.Type() != byteSliceType && !flect.ConvertsString(fv.Type()) {
+		var parts []string
+		for _, value := range values {
+			for _, part := range strings.Split(value, ",") {
+				parts = append(parts, strings.TrimSpace(part))
+			}
+		}
+		return flect.SetValues(fv, parts)
+	}
+
+	return flect.SetValue(fv, values[0])
+}
+
 type bodyInApplier struct{}
 
+// Apply sets the field to the request's body. A form's body is parsed rather than bound (see bindForm), so the field is
+// left unset.
 func (bodyInApplier) Apply(_ reflect.Value, fv reflect.Value, tc *structs.ToolContext) gomerr.Gomerr {
-	fv.Set(reflect.ValueOf(tc.Get(bodyBytesKey)))
+	body := tc.Get(bodyKey)
+	if body == nil {
+		return nil
+	}
+
+	fv.Set(reflect.ValueOf(body))
 	return nil
 }
 
-var byteSliceType = reflect.TypeOf([]byte{})
+var (
+	byteSliceType  = reflect.TypeOf([]byte{})
+	readCloserType = reflect.TypeOf((*io.ReadCloser)(nil)).Elem()
+)
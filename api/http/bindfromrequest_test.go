@@ -13,12 +13,17 @@ import (
 	"github.com/jt0/gomer/_test/helpers/stores"
 	. "github.com/jt0/gomer/api/http"
 	"github.com/jt0/gomer/auth"
+	"github.com/jt0/gomer/gomerr"
 	"github.com/jt0/gomer/resource"
 )
 
 var (
 	subject = auth.NewSubject(auth.ReadWriteAllFields)
 	actions = map[interface{}]func() resource.Action{PostCollection: resource.CreateAction}
+
+	// Resource types are registered once (a repeated Register returns the existing metadata), so each test that stores
+	// instances clears the shared store first rather than using its own.
+	store = stores.NewMemoryStore()
 )
 
 //goland:noinspection GoSnakeCaseUsage
@@ -103,6 +108,35 @@ func TestBindInTypes(t *testing.T) {
 	}
 }
 
+type Profile struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	Name  string `in:"+" out:"name"`
+	Email string `in:"+" out:"email"`
+}
+
+func TestFieldSelectionVerifiedBeforeAction(t *testing.T) {
+	_, ge := resource.Register(&Profile{}, nil, actions, stores.PanicStore, nil)
+	assert.Success(t, ge)
+
+	request := func(fields string) *http.Request {
+		return &http.Request{Method: http.MethodPost, URL: &url.URL{Path: "/", RawQuery: "fields=" + fields}, Body: body(`{"Name": "n"}`)}
+	}
+
+	r, ge := BindFromRequest(request("name,email"), reflect.TypeOf(&Profile{}), subject, "create")
+	assert.Success(t, ge)
+	assert.Equals(t, 0, len(r.(*Profile).SelectedAttributes())) // only reads are limited to the selected fields
+
+	get := &http.Request{Method: http.MethodGet, URL: &url.URL{Path: "/", RawQuery: "fields=email"}}
+	r, ge = BindFromRequest(get, reflect.TypeOf(&Profile{}), subject, "read")
+	assert.Success(t, ge)
+	assert.Equals(t, []string{"Email"}, r.(*Profile).SelectedAttributes())
+
+	_, ge = BindFromRequest(request("name,bogus"), reflect.TypeOf(&Profile{}), subject, "create")
+	assert.ErrorType(t, ge, &gomerr.BadValueError{}, "Expected an unknown field to be rejected before the action")
+	assert.Equals(t, "bogus", ge.(*gomerr.BadValueError).Value)
+}
+
 func body(input string) io.ReadCloser {
 	return ioutil.NopCloser(strings.NewReader(input))
 }
@@ -1,14 +1,16 @@
 package http
 
 import (
-	"encoding/json"
+	"bytes"
 	"fmt"
+	"io"
 	"net/http"
 	"reflect"
 	"strconv"
 	"strings"
 
 	bind2 "github.com/jt0/gomer/bind"
+	"github.com/jt0/gomer/flect"
 	"github.com/jt0/gomer/gomerr"
 	"github.com/jt0/gomer/structs"
 )
@@ -21,7 +23,6 @@ type BindToResponseConfiguration struct {
 
 	defaultContentType             string
 	perContentTypeMarshalFunctions map[string]Marshal
-	defaultMarshalFunction         Marshal
 }
 
 // Marshal provides a function to convert the toMarshal to bytes suitable for returning in a response body.
@@ -32,11 +33,25 @@ func NewBindToResponseConfiguration() BindToResponseConfiguration {
 		BindConfiguration:              bind2.NewConfiguration(),
 		BindDirectiveConfiguration:     NewBindDirectiveConfiguration(),
 		defaultContentType:             DefaultContentType,
-		perContentTypeMarshalFunctions: make(map[string]Marshal),
-		defaultMarshalFunction:         json.Marshal,
+		perContentTypeMarshalFunctions: builtInMarshalFunctions(),
 	}
 }
 
+// AddMarshalFunction sets the function used to marshal responses for the content type. A nil function removes support
+// for the content type.
+func (c *BindToResponseConfiguration) AddMarshalFunction(contentType string, marshal Marshal) {
+	if marshal == nil {
+		delete(c.perContentTypeMarshalFunctions, normalizedMediaType(contentType))
+	} else {
+		c.perContentTypeMarshalFunctions[normalizedMediaType(contentType)] = marshal
+	}
+}
+
+// RegisterMarshalFunction adds the marshal function to the current BindToResponseConfiguration.
+func RegisterMarshalFunction(contentType string, marshal Marshal) {
+	responseConfig.AddMarshalFunction(contentType, marshal)
+}
+
 var (
 	DefaultBindToResponseTool *structs.Tool
 	responseConfig            BindToResponseConfiguration
@@ -57,54 +72,163 @@ func SetBindToResponseConfiguration(responseConfiguration BindToResponseConfigur
 }
 
 // BindToResponse
-// TODO: add support for data format type
-func BindToResponse(result reflect.Value, header http.Header, scope string, acceptLanguage string) (output []byte, ge gomerr.Gomerr) {
-	tc := structs.ToolContextWithScope(scope).Put(headersKey, header).Put(AcceptLanguageKey, acceptLanguage)
+//
+// An optional ToolContext can be provided to customize the output, e.g. with AddAcceptToContext and
+// AddFieldSelectionToContext. If none of the content types with a registered marshal function (see
+// RegisterMarshalFunction) are acceptable, a NotAcceptableError is returned.
+//
+// A 'body' field of type io.Reader or io.WriterTo is read into the returned bytes. Use WriteResponse to stream it
+// instead.
+func BindToResponse(result reflect.Value, header http.Header, scope string, acceptLanguage string, tcs ...*structs.ToolContext) (output []byte, ge gomerr.Gomerr) {
+	output, stream, ge := bindToResponse(result, header, scope, acceptLanguage, tcs...)
+	if ge != nil || stream == nil {
+		return output, ge
+	}
+
+	var buffer bytes.Buffer
+	if ge = writeStream(&buffer, stream); ge != nil {
+		return nil, ge
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// WriteResponse binds the result in the same way as BindToResponse and writes the response's body to w. A 'body' field
+// of type io.Reader or io.WriterTo is copied to w without first being read into memory, and is closed afterwards if it
+// is an io.Closer. Since writing may begin before the copy is complete, the response's status code must be set
+// beforehand.
+func WriteResponse(w io.Writer, result reflect.Value, header http.Header, scope string, acceptLanguage string, tcs ...*structs.ToolContext) gomerr.Gomerr {
+	output, stream, ge := bindToResponse(result, header, scope, acceptLanguage, tcs...)
+	if ge != nil {
+		return ge
+	}
+
+	if stream != nil {
+		return writeStream(w, stream)
+	}
+
+	if _, err := w.Write(output); err != nil {
+		return gomerr.Internal("Failed to write response body").Wrap(err)
+	}
+
+	return nil
+}
+
+// bindToResponse returns either the response's bytes or, for a streamed body, the io.Reader or io.WriterTo to copy.
+func bindToResponse(result reflect.Value, header http.Header, scope string, acceptLanguage string, tcs ...*structs.ToolContext) ([]byte, interface{}, gomerr.Gomerr) {
+	tc := structs.EnsureContext(tcs...).PutScope(scope).Put(headersKey, header).Put(AcceptLanguageKey, acceptLanguage)
+
+	// Ensures the 'body' binding (if any) is known before choosing how to produce the output
+	if ge := structs.Preprocess(result.Type(), DefaultBindToResponseTool); ge != nil {
+		return nil, nil, ge
+	}
 
 	outBodyBinding := hasOutBodyBinding[result.Type().String()]
 	if !outBodyBinding {
 		tc.Put(bind2.OutKey, make(map[string]interface{}))
 	}
 
-	if ge = structs.ApplyTools(result, tc, DefaultBindToResponseTool); ge != nil {
-		return nil, ge
+	if ge := structs.ApplyTools(result, tc, DefaultBindToResponseTool); ge != nil {
+		return nil, nil, ge
+	}
+
+	if ge := bind2.VerifyFieldSelection(tc); ge != nil {
+		return nil, nil, ge
 	}
 
 	if outBodyBinding {
-		return tc.Get(bodyBytesKey).([]byte), nil
-	} else {
-		// based on content type, and the absence of any "body" attributes use the proper marshaler to put the
-		// data into the response bytes
-		// TODO:p3 Allow applications to provide alternative means to choose a marshaler
-		contentType := header.Get(AcceptsHeader) // TODO:p4 support multi-options
-		marshal, ok := responseConfig.perContentTypeMarshalFunctions[contentType]
-		if !ok {
-			if responseConfig.defaultMarshalFunction == nil {
-				return nil, gomerr.Marshal("Unsupported Accepts content type", contentType)
-			}
-			marshal = responseConfig.defaultMarshalFunction
-			contentType = DefaultContentType
+		switch body := tc.Get(bodyKey).(type) {
+		case []byte:
+			return body, nil, nil
+		case nil:
+			return nil, nil, nil
+		default:
+			return nil, body, nil
 		}
+	}
 
-		outMap := tc.Get(bind2.OutKey).(map[string]interface{})
-		if len(outMap) == 0 && responseConfig.EmptyValueHandlingDefault == OmitEmpty {
-			return nil, ge
-		}
+	// based on the request's Accept header, and the absence of any "body" attributes use the proper marshaler to put
+	// the data into the response bytes
+	// TODO:p3 Allow applications to provide alternative means to choose a marshaler
+	accept, _ := tc.Get(AcceptKey).(string)
+	supported := supportedContentTypes(responseConfig.perContentTypeMarshalFunctions)
+	contentType, ok := negotiate(accept, responseConfig.defaultContentType, supported)
+	marshal := responseConfig.perContentTypeMarshalFunctions[contentType]
+	if !ok || marshal == nil {
+		return nil, nil, NotAcceptable(accept, supported)
+	}
 
-		bytes, err := marshal(outMap)
-		if err != nil {
-			return nil, gomerr.Marshal("Unable to marshal data", outMap).AddAttribute("ContentType", contentType).Wrap(err)
-		}
-		header.Set(ContentTypeHeader, contentType)
+	outMap := tc.Get(bind2.OutKey).(map[string]interface{})
+	if len(outMap) == 0 && responseConfig.EmptyValueHandlingDefault == OmitEmpty {
+		return nil, nil, nil
+	}
 
-		return bytes, nil
+	output, err := marshal(outMap)
+	if err != nil {
+		return nil, nil, gomerr.Marshal("Unable to marshal data", outMap).AddAttribute("ContentType", contentType).Wrap(err)
 	}
+	header.Set(ContentTypeHeader, contentType)
+
+	return output, nil, nil
+}
+
+func writeStream(w io.Writer, stream interface{}) gomerr.Gomerr {
+	if closer, ok := stream.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	var err error
+	if writerTo, ok := stream.(io.WriterTo); ok {
+		_, err = writerTo.WriteTo(w)
+	} else {
+		_, err = io.Copy(w, stream.(io.Reader))
+	}
+	if err != nil {
+		return gomerr.Internal("Failed to write response body").Wrap(err)
+	}
+
+	return nil
+}
+
+// AddAcceptToContext adds the request's Accept header value to the context so BindToResponse can choose the content
+// type of the response. Without it, the default content type is used.
+func AddAcceptToContext(request *http.Request, tcs ...*structs.ToolContext) *structs.ToolContext {
+	return structs.EnsureContext(tcs...).Put(AcceptKey, request.Header.Get(AcceptHeader))
+}
+
+// AddFieldSelectionToContext limits the fields included in a response to the ones listed (comma-separated) in the
+// request's FieldsQueryParam, e.g. "?fields=id,name,owner.email". Names are those used in the response (see
+// bind.AddFieldSelectionToContext), and BindToResponse returns an error if any are unknown.
+func AddFieldSelectionToContext(request *http.Request, tcs ...*structs.ToolContext) *structs.ToolContext {
+	var fields []string
+	for _, value := range request.URL.Query()[FieldsQueryParam] {
+		fields = append(fields, strings.Split(value, ",")...)
+	}
+
+	return bind2.AddFieldSelectionToContext(fields, tcs...)
+}
+
+// verifyFieldSelection checks that the fields selected by the request (if any) are output names of the resource type's
+// response for the scope. It returns the names of the fields whose values produce the selected output, or nil if they
+// aren't all known (see bind.SelectedFieldNames).
+func verifyFieldSelection(request *http.Request, resourceType reflect.Type, scope string) ([]string, gomerr.Gomerr) {
+	tc := AddFieldSelectionToContext(request)
+	if tc.Get(bind2.FieldsKey) == nil {
+		return nil, nil
+	}
+
+	tc.PutScope(scope).Put(headersKey, http.Header{})
+	if ge := bind2.VerifyFieldSelectionForType(resourceType, DefaultBindToResponseTool, tc); ge != nil {
+		return nil, ge
+	}
+
+	return bind2.SelectedFieldNames(tc), nil
 }
 
 // bindToResponseExtension
 //
 // header.<name> -> Header with name <name>
-// body          -> Body for the response
+// body          -> Body for the response ([]byte, io.Reader, or io.WriterTo)
 type bindToResponseExtension struct{}
 
 func (bindToResponseExtension) Applier(structType reflect.Type, structField reflect.StructField, directive string, _ string) (structs.Applier, gomerr.Gomerr) {
@@ -115,8 +239,8 @@ func (bindToResponseExtension) Applier(structType reflect.Type, structField refl
 		}
 		return bindResponseHeaderApplier{headerName}, nil
 	} else if directive == responseConfig.BindBody {
-		if structField.Type != byteSliceType {
-			return nil, gomerr.Configuration("Body field must be of type []byte, not: " + structField.Type.String())
+		if ft := structField.Type; ft != byteSliceType && !ft.Implements(readerType) && !ft.Implements(writerToType) {
+			return nil, gomerr.Configuration("Body field must be of type []byte, io.Reader, or io.WriterTo, not: " + ft.String())
 		}
 		hasOutBodyBinding[structType.String()] = true
 		return bodyOutApplier{}, nil
@@ -153,6 +277,13 @@ func (b bindResponseHeaderApplier) Apply(_ reflect.Value, fv reflect.Value, tc *
 	}
 
 	var headerVal string
+	if converted, ok, ge := flect.Convert(fv.Interface(), stringType); ge != nil {
+		return ge
+	} else if ok {
+		tc.Get(headersKey).(http.Header).Add(b.name, fmt.Sprint(converted))
+		return nil
+	}
+
 	switch val := fv.Interface().(type) {
 	case string:
 		headerVal = val
@@ -205,10 +336,20 @@ func (b bindResponseHeaderApplier) Apply(_ reflect.Value, fv reflect.Value, tc *
 type bodyOutApplier struct{}
 
 func (bodyOutApplier) Apply(_ reflect.Value, fv reflect.Value, tc *structs.ToolContext) gomerr.Gomerr {
-	tc.Put(bodyBytesKey, fv.Interface())
+	if (fv.Kind() == reflect.Interface || fv.Kind() == reflect.Ptr) && fv.IsNil() {
+		return nil
+	}
+
+	tc.Put(bodyKey, fv.Interface())
 	return nil
 }
 
+var (
+	readerType   = reflect.TypeOf((*io.Reader)(nil)).Elem()
+	writerToType = reflect.TypeOf((*io.WriterTo)(nil)).Elem()
+	stringType   = reflect.TypeOf("")
+)
+
 var directiveFunctions = map[string]func(reflect.Value) bool{
 	"?":    reflect.Value.IsZero,
 	"then": func(reflect.Value) bool { return false },
@@ -0,0 +1,62 @@
+package http_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jt0/gomer/_test/assert"
+	"github.com/jt0/gomer/_test/helpers/stores"
+	. "github.com/jt0/gomer/api/http"
+	"github.com/jt0/gomer/limit"
+	"github.com/jt0/gomer/resource"
+)
+
+type Upload struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	Content io.ReadCloser `in:"body"`
+}
+
+type Download struct {
+	Content io.Reader `out:"body"`
+}
+
+type Blob struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	Data []byte `in:"body"`
+}
+
+func TestStreamedBodies(t *testing.T) {
+	_, ge := resource.Register(&Upload{}, nil, actions, stores.PanicStore, nil)
+	assert.Success(t, ge)
+	_, ge = resource.Register(&Blob{}, nil, actions, stores.PanicStore, nil)
+	assert.Success(t, ge)
+
+	config := NewBindFromRequestConfiguration()
+	config.MaxBodySize = 8
+	SetBindFromRequestConfiguration(config)
+	defer SetBindFromRequestConfiguration(NewBindFromRequestConfiguration())
+
+	r, ge := BindFromRequest(&http.Request{URL: &url.URL{Path: "/"}, Body: body("0123456789")}, reflect.TypeOf(&Upload{}), subject, "some_scope")
+	assert.Success(t, ge)
+	read, err := io.ReadAll(r.(*Upload).Content)
+	assert.Equals(t, "01234567", string(read))
+	assert.Error(t, err, "Expected reading past the maximum body size to fail")
+
+	_, ge = BindFromRequest(&http.Request{URL: &url.URL{Path: "/"}, Body: body("0123456789")}, reflect.TypeOf(&Blob{}), subject, "some_scope")
+	assert.ErrorType(t, ge, &limit.ExceededError{}, "Expected a buffered body past the maximum size to fail")
+
+	var w bytes.Buffer
+	assert.Success(t, WriteResponse(&w, reflect.ValueOf(Download{Content: strings.NewReader("streamed")}), http.Header{}, "", ""))
+	assert.Equals(t, "streamed", w.String())
+
+	output, ge := BindToResponse(reflect.ValueOf(Download{Content: strings.NewReader("buffered")}), http.Header{}, "", "")
+	assert.Success(t, ge)
+	assert.Equals(t, "buffered", string(output))
+}
@@ -0,0 +1,294 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ugorji/go/codec"
+	"gopkg.in/yaml.v2"
+
+	bind2 "github.com/jt0/gomer/bind"
+	"github.com/jt0/gomer/gomerr"
+)
+
+const (
+	JsonContentType    = "application/json"
+	YamlContentType    = "application/yaml"
+	CborContentType    = "application/cbor"
+	MsgpackContentType = "application/msgpack"
+	FormContentType    = "application/x-www-form-urlencoded"
+
+	// Patch content types bind the fields they change explicitly (see BindFromRequest)
+	MergePatchContentType = "application/merge-patch+json"
+	JsonPatchContentType  = "application/json-patch+json"
+
+	MultipartFormContentType = "multipart/form-data" // Only supported with 'form.' and 'file.' bindings
+
+	formKeySeparator = "."
+)
+
+var (
+	mapType = reflect.TypeOf(map[string]interface{}(nil))
+
+	cborHandle    = &codec.CborHandle{}
+	msgpackHandle = &codec.MsgpackHandle{}
+)
+
+func init() {
+	cborHandle.MapType = mapType
+	msgpackHandle.MapType = mapType
+	msgpackHandle.RawToString = true
+	msgpackHandle.WriteExt = true
+}
+
+func builtInMarshalFunctions() map[string]Marshal {
+	return map[string]Marshal{
+		JsonContentType:    json.Marshal,
+		YamlContentType:    yaml.Marshal,
+		CborContentType:    codecMarshal(cborHandle),
+		MsgpackContentType: codecMarshal(msgpackHandle),
+		FormContentType:    formMarshal,
+	}
+}
+
+func builtInUnmarshalFunctions() map[string]Unmarshal {
+	return map[string]Unmarshal{
+		JsonContentType:    json.Unmarshal,
+		YamlContentType:    yamlUnmarshal,
+		CborContentType:    codecUnmarshal(cborHandle),
+		MsgpackContentType: codecUnmarshal(msgpackHandle),
+		FormContentType:    formUnmarshal,
+
+		MergePatchContentType: json.Unmarshal,
+		JsonPatchContentType:  jsonPatchUnmarshal,
+	}
+}
+
+func codecMarshal(handle codec.Handle) Marshal {
+	return func(toMarshal interface{}) ([]byte, error) {
+		var out []byte
+		err := codec.NewEncoderBytes(&out, handle).Encode(toMarshal)
+		return out, err
+	}
+}
+
+func codecUnmarshal(handle codec.Handle) Unmarshal {
+	return func(toUnmarshal []byte, ptrToTarget interface{}) error {
+		return codec.NewDecoderBytes(toUnmarshal, handle).Decode(ptrToTarget)
+	}
+}
+
+// yamlUnmarshal converts the map[interface{}]interface{} values yaml.v2 produces for nested mappings to
+// map[string]interface{} so that they can be bound like the output of the other unmarshalers.
+func yamlUnmarshal(toUnmarshal []byte, ptrToTarget interface{}) error {
+	if err := yaml.Unmarshal(toUnmarshal, ptrToTarget); err != nil {
+		return err
+	}
+
+	if m, ok := ptrToTarget.(*map[string]interface{}); ok {
+		for k, v := range *m {
+			(*m)[k] = stringKeyed(v)
+		}
+	}
+
+	return nil
+}
+
+func stringKeyed(v interface{}) interface{} {
+	switch tv := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(tv))
+		for k, mv := range tv {
+			m[fmt.Sprint(k)] = stringKeyed(mv)
+		}
+		return m
+	case []interface{}:
+		for i, sv := range tv {
+			tv[i] = stringKeyed(sv)
+		}
+	}
+	return v
+}
+
+// formMarshal encodes a map as application/x-www-form-urlencoded data. Nested maps use '.'-separated keys (e.g.
+// "owner.email=...") and slices use repeated keys.
+func formMarshal(toMarshal interface{}) ([]byte, error) {
+	m, ok := toMarshal.(map[string]interface{})
+	if !ok {
+		return nil, gomerr.Marshal("Form data must be a map[string]interface{}", toMarshal)
+	}
+
+	values := url.Values{}
+	addFormValues(values, "", m)
+
+	return []byte(values.Encode()), nil
+}
+
+func addFormValues(values url.Values, prefix string, v interface{}) {
+	switch tv := v.(type) {
+	case nil:
+	case map[string]interface{}:
+		keys := make([]string, 0, len(tv))
+		for k := range tv {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			if prefix != "" {
+				addFormValues(values, prefix+formKeySeparator+k, tv[k])
+			} else {
+				addFormValues(values, k, tv[k])
+			}
+		}
+	case []interface{}:
+		for _, sv := range tv {
+			addFormValues(values, prefix, sv)
+		}
+	default:
+		values.Add(prefix, fmt.Sprint(tv))
+	}
+}
+
+// formUnmarshal decodes application/x-www-form-urlencoded data into a map[string]interface{}. A key with more than one
+// value becomes a []interface{}, and '.'-separated keys become nested maps.
+func formUnmarshal(toUnmarshal []byte, ptrToTarget interface{}) error {
+	m, ok := ptrToTarget.(*map[string]interface{})
+	if !ok || m == nil {
+		return gomerr.Unmarshal("Form data can only be unmarshaled to a *map[string]interface{}", toUnmarshal, ptrToTarget)
+	}
+
+	values, err := url.ParseQuery(string(toUnmarshal))
+	if err != nil {
+		return err
+	}
+
+	if *m == nil {
+		*m = make(map[string]interface{}, len(values))
+	}
+	addFormValuesToMap(*m, values)
+
+	return nil
+}
+
+func addFormValuesToMap(m map[string]interface{}, values url.Values) {
+	for key, vs := range values {
+		var value interface{} = vs[0]
+		if len(vs) > 1 {
+			multiple := make([]interface{}, len(vs))
+			for i, v := range vs {
+				multiple[i] = v
+			}
+			value = multiple
+		}
+
+		target := m
+		parts := strings.Split(key, formKeySeparator)
+		for _, part := range parts[:len(parts)-1] {
+			nested, ok := target[part].(map[string]interface{})
+			if !ok {
+				nested = make(map[string]interface{})
+				target[part] = nested
+			}
+			target = nested
+		}
+		target[parts[len(parts)-1]] = value
+	}
+}
+
+type jsonPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// jsonPatchUnmarshal decodes an RFC 6902 JSON Patch document into the equivalent merge patch (i.e. RFC 7396) map. Only
+// the 'add', 'replace', and 'remove' operations are supported, and their paths must refer to object members (e.g.
+// "/owner/email") rather than array elements. A removed member's value is nil, whereas one that's set to null has the
+// value bind.Null.
+//
+// A 'replace' operation's member must exist. If the patch already sets the member's top-level one, it's checked
+// against the patch. Otherwise, the top-level member's value is wrapped in a bind.Replacement so the field it's bound
+// to can be checked against the stored instance (see BindFromRequest).
+func jsonPatchUnmarshal(toUnmarshal []byte, ptrToTarget interface{}) error {
+	m, ok := ptrToTarget.(*map[string]interface{})
+	if !ok || m == nil {
+		return gomerr.Unmarshal("JSON Patch data can only be unmarshaled to a *map[string]interface{}", toUnmarshal, ptrToTarget)
+	}
+
+	var operations []jsonPatchOperation
+	if err := json.Unmarshal(toUnmarshal, &operations); err != nil {
+		return err
+	}
+
+	if *m == nil {
+		*m = make(map[string]interface{}, len(operations))
+	}
+
+	replaced := make(map[string]bool)
+	for i, operation := range operations {
+		var value interface{}
+		switch operation.Op {
+		case "add", "replace":
+			if value = operation.Value; value == nil {
+				value = bind2.Null
+			}
+		case "remove":
+		default:
+			return gomerr.InvalidValue("op", operation.Op, []string{"add", "replace", "remove"}).AddAttribute("Operation", i)
+		}
+
+		if operation.Path == "" || operation.Path[0] != '/' {
+			return gomerr.InvalidValue("path", operation.Path, "JSON pointer to an object member").AddAttribute("Operation", i)
+		}
+
+		tokens := strings.Split(operation.Path[1:], "/")
+		for t, token := range tokens {
+			tokens[t] = jsonPointerUnescaper.Replace(token)
+		}
+
+		if operation.Op == "replace" {
+			if _, inPatch := (*m)[tokens[0]]; !inPatch {
+				replaced[tokens[0]] = true
+			} else if !hasMember(*m, tokens) {
+				return gomerr.InvalidValue("path", operation.Path, "existing member").AddAttribute("Operation", i)
+			}
+		}
+
+		target := *m
+		for _, token := range tokens[:len(tokens)-1] {
+			nested, ok := target[token].(map[string]interface{})
+			if !ok {
+				nested = make(map[string]interface{})
+				target[token] = nested
+			}
+			target = nested
+		}
+		target[tokens[len(tokens)-1]] = value
+	}
+
+	for name := range replaced {
+		(*m)[name] = bind2.Replacement{Value: (*m)[name]}
+	}
+
+	return nil
+}
+
+// hasMember returns true if the path's tokens refer to a member of m that hasn't been removed.
+func hasMember(m map[string]interface{}, tokens []string) bool {
+	for _, token := range tokens[:len(tokens)-1] {
+		nested, ok := m[token].(map[string]interface{})
+		if !ok {
+			return false
+		}
+		m = nested
+	}
+
+	return m[tokens[len(tokens)-1]] != nil
+}
+
+var jsonPointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
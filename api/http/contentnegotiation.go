@@ -0,0 +1,150 @@
+package http
+
+import (
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jt0/gomer/gomerr"
+)
+
+// NotAcceptableError indicates that none of the media types a response can be marshaled to are acceptable based on the
+// request's Accept header.
+type NotAcceptableError struct {
+	gomerr.Gomerr
+	Accept    string
+	Supported []string
+}
+
+func NotAcceptable(accept string, supported []string) *NotAcceptableError {
+	return gomerr.Build(new(NotAcceptableError), accept, supported).(*NotAcceptableError)
+}
+
+func (*NotAcceptableError) StatusCode() int {
+	return http.StatusNotAcceptable
+}
+
+// UnsupportedMediaTypeError indicates that a request's body can't be unmarshaled because of its Content-Type.
+type UnsupportedMediaTypeError struct {
+	gomerr.Gomerr
+	ContentType string
+	Supported   []string
+}
+
+func UnsupportedMediaType(contentType string, supported []string) *UnsupportedMediaTypeError {
+	return gomerr.Build(new(UnsupportedMediaTypeError), contentType, supported).(*UnsupportedMediaTypeError)
+}
+
+func (*UnsupportedMediaTypeError) StatusCode() int {
+	return http.StatusUnsupportedMediaType
+}
+
+type mediaRange struct {
+	mediaType string // e.g. "application/json", "application/*", or "*/*"
+	quality   float64
+}
+
+// parseAccept returns the media ranges listed in an Accept header value. Ranges that can't be parsed are ignored.
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || strings.Count(mediaType, "/") != 1 {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil || quality < 0 || quality > 1 {
+				continue
+			}
+		}
+
+		ranges = append(ranges, mediaRange{mediaType: mediaType, quality: quality})
+	}
+
+	return ranges
+}
+
+// quality returns the quality value of the most specific range that matches the media type, or 0 if none do.
+func quality(mediaType string, ranges []mediaRange) float64 {
+	mainType := mediaType[:strings.IndexByte(mediaType, '/')+1]
+
+	q, specificity := 0.0, 0
+	for _, r := range ranges {
+		var s int
+		switch {
+		case r.mediaType == mediaType:
+			s = 3
+		case r.mediaType == mainType+"*":
+			s = 2
+		case r.mediaType == "*/*":
+			s = 1
+		default:
+			continue
+		}
+
+		if s > specificity {
+			q, specificity = r.quality, s
+		}
+	}
+
+	return q
+}
+
+// negotiate returns the supported media type with the highest quality value in the Accept header. If more than one has
+// the same quality, the default one is preferred, followed by the others in the order given. An empty Accept header
+// accepts any media type.
+func negotiate(accept string, defaultMediaType string, supported []string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		accept = "*/*"
+	}
+
+	ranges := parseAccept(accept)
+
+	candidates := make([]string, 0, len(supported)+1)
+	for _, mediaType := range supported {
+		if mediaType == defaultMediaType {
+			candidates = append([]string{mediaType}, candidates...)
+		} else {
+			candidates = append(candidates, mediaType)
+		}
+	}
+
+	best, bestQuality := "", 0.0
+	for _, mediaType := range candidates {
+		if q := quality(mediaType, ranges); q > bestQuality {
+			best, bestQuality = mediaType, q
+		}
+	}
+
+	return best, best != ""
+}
+
+// normalizedMediaType removes any parameters (e.g. "; charset=utf-8") and lowercases the value.
+func normalizedMediaType(contentType string) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mediaType
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+// supportedContentTypes returns the (sorted) keys of either a Marshal or Unmarshal function map.
+func supportedContentTypes(functions interface{}) []string {
+	var contentTypes []string
+	switch f := functions.(type) {
+	case map[string]Marshal:
+		for contentType := range f {
+			contentTypes = append(contentTypes, contentType)
+		}
+	case map[string]Unmarshal:
+		for contentType := range f {
+			contentTypes = append(contentTypes, contentType)
+		}
+	}
+	sort.Strings(contentTypes)
+
+	return contentTypes
+}
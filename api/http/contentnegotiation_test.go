@@ -0,0 +1,74 @@
+package http_test
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/jt0/gomer/_test/assert"
+	"github.com/jt0/gomer/_test/helpers/stores"
+	. "github.com/jt0/gomer/api/http"
+	"github.com/jt0/gomer/resource"
+)
+
+func TestBindFromRequestContentTypes(t *testing.T) {
+	_, ge := resource.Register(&Greeting{}, nil, actions, stores.PanicStore, nil)
+	assert.Success(t, ge)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{name: "Json", contentType: "application/json; charset=utf-8", body: `{"Style": "hello", "Recipient": "kitty"}`},
+		{name: "Yaml", contentType: YamlContentType, body: "Style: hello\nRecipient: kitty\n"},
+		{name: "Form", contentType: FormContentType, body: "Style=hello&Recipient=kitty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := &http.Request{URL: &url.URL{Path: "/"}, Header: http.Header{ContentTypeHeader: []string{tt.contentType}}, Body: body(tt.body)}
+			r, ge := BindFromRequest(request, reflect.TypeOf(&Greeting{}), subject, "some_scope")
+			assert.Success(t, ge)
+			assert.Equals(t, "hello", r.(*Greeting).Style_body)
+			assert.Equals(t, "kitty", r.(*Greeting).Recipient_body)
+		})
+	}
+
+	request := &http.Request{URL: &url.URL{Path: "/"}, Header: http.Header{ContentTypeHeader: []string{"text/csv"}}, Body: body("hello,kitty")}
+	_, ge = BindFromRequest(request, reflect.TypeOf(&Greeting{}), subject, "some_scope")
+	assert.ErrorType(t, ge, &UnsupportedMediaTypeError{}, "Expected an unsupported content type to fail")
+}
+
+type Reply struct {
+	Message string `out:"+"`
+}
+
+func TestBindToResponseNegotiatesContentType(t *testing.T) {
+	tests := []struct {
+		name     string
+		accept   string
+		expected string
+	}{
+		{name: "NoAccept", accept: "", expected: JsonContentType},
+		{name: "Exact", accept: MsgpackContentType, expected: MsgpackContentType},
+		{name: "QualityValues", accept: "application/xml, application/yaml;q=0.8, application/cbor;q=0.5", expected: YamlContentType},
+		{name: "WildcardPrefersDefault", accept: "application/*", expected: JsonContentType},
+		{name: "MoreSpecificRangeWins", accept: "application/*;q=0.2, application/json;q=0, application/yaml;q=0.3", expected: YamlContentType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			request := &http.Request{Header: http.Header{AcceptHeader: []string{tt.accept}}}
+			_, ge := BindToResponse(reflect.ValueOf(Reply{Message: "hi"}), header, "", "", AddAcceptToContext(request))
+			assert.Success(t, ge)
+			assert.Equals(t, tt.expected, header.Get(ContentTypeHeader))
+		})
+	}
+
+	request := &http.Request{Header: http.Header{AcceptHeader: []string{"text/html, application/json;q=0"}}}
+	_, ge := BindToResponse(reflect.ValueOf(Reply{Message: "hi"}), http.Header{}, "", "", AddAcceptToContext(request))
+	assert.ErrorType(t, ge, &NotAcceptableError{}, "Expected no acceptable content type to fail")
+}
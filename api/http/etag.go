@@ -0,0 +1,120 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/jt0/gomer/data"
+	"github.com/jt0/gomer/data/dataerr"
+	"github.com/jt0/gomer/gomerr"
+	"github.com/jt0/gomer/resource"
+)
+
+// PreconditionFailedError indicates that a conditional request's If-Match header doesn't match the current entity tag
+// of the instance it would change.
+type PreconditionFailedError struct {
+	gomerr.Gomerr
+	IfMatch string
+	ETag    string
+}
+
+func PreconditionFailed(ifMatch string, etag string) *PreconditionFailedError {
+	return gomerr.Build(new(PreconditionFailedError), ifMatch, etag).(*PreconditionFailedError)
+}
+
+func (*PreconditionFailedError) StatusCode() int {
+	return http.StatusPreconditionFailed
+}
+
+// ETag returns the (strong) entity tag of an instance. If the instance is data.Versioned, the tag is its version.
+// Otherwise, it's a hash of the instance's response content as bound by BindToResponse for the read scope, so that the
+// tag is the same whichever action's response it's returned with. An instance whose response body is streamed has no
+// tag, so an empty string is returned.
+func ETag(instance reflect.Value) (string, gomerr.Gomerr) {
+	if p, ok := instance.Addr().Interface().(data.Persistable); ok {
+		if _, version, versioned := data.Version(p); versioned {
+			return strconv.Quote(strconv.FormatInt(version, 10)), nil
+		}
+	}
+
+	output, stream, ge := bindToResponse(instance, http.Header{}, resource.ReadAction().Name(), "")
+	if ge != nil || stream != nil {
+		return "", ge
+	}
+
+	hash := sha256.Sum256(output)
+	return strconv.Quote(base64.RawURLEncoding.EncodeToString(hash[:18])), nil
+}
+
+// NotModified returns true if the request's If-None-Match header matches the entity tag, in which case a GET or HEAD
+// request can be answered with a 304 (Not Modified) response.
+func NotModified(request *http.Request, etag string) bool {
+	ifNoneMatch := request.Header.Get(IfNoneMatchHeader)
+	return ifNoneMatch != "" && etag != "" && etagsMatch(ifNoneMatch, etag, false)
+}
+
+// ifMatchPrecondition returns a resource.Precondition that verifies the request's If-Match header matches the current
+// instance's entity tag.
+func ifMatchPrecondition(ifMatch string) resource.Precondition {
+	return func(current resource.Instance) gomerr.Gomerr {
+		etag, ge := ETag(reflect.ValueOf(current).Elem())
+		if ge != nil {
+			return ge
+		}
+
+		if etag == "" || !etagsMatch(ifMatch, etag, true) {
+			return PreconditionFailed(ifMatch, etag)
+		}
+
+		return nil
+	}
+}
+
+// PreconditionFailedIfVersionChanged returns a PreconditionFailedError that wraps ge if ge is (or wraps) a
+// dataerr.VersionMismatchError and the request has an If-Match header. This happens when the instance is changed by
+// another request after its If-Match header has been checked. Otherwise, ge is returned.
+func PreconditionFailedIfVersionChanged(request *http.Request, ge gomerr.Gomerr) gomerr.Gomerr {
+	ifMatch := request.Header.Get(IfMatchHeader)
+	var versionMismatch *dataerr.VersionMismatchError
+	if ifMatch == "" || !errors.As(ge, &versionMismatch) {
+		return ge
+	}
+
+	return PreconditionFailed(ifMatch, "").Wrap(ge)
+}
+
+// etagsMatch returns true if any of the comma-separated entity tags in the header value matches the etag or if the
+// value is "*". A strong comparison never matches a weak ("W/") tag.
+func etagsMatch(headerValue string, etag string, strong bool) bool {
+	for _, candidate := range strings.Split(headerValue, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+
+		if strings.HasPrefix(candidate, "W/") {
+			if strong {
+				continue
+			}
+			candidate = candidate[2:]
+		}
+
+		if candidate == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// conditionalMethods are those for which an If-Match header is enforced.
+var conditionalMethods = map[string]bool{
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
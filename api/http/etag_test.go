@@ -0,0 +1,114 @@
+package http_test
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/jt0/gomer/_test/assert"
+	. "github.com/jt0/gomer/api/http"
+	"github.com/jt0/gomer/data/dataerr"
+	"github.com/jt0/gomer/resource"
+)
+
+type Article struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	ArticleId string `in:"path.1" id:"+" out:"+"`
+	Title     string `in:"+" out:"+"`
+	Revision  int    `out:"+"`
+}
+
+func (*Article) VersionField() string {
+	return "Revision"
+}
+
+type Note struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	NoteId string `in:"path.1" id:"+" out:"+"`
+	Text   string `in:"+" out:"+"`
+}
+
+var conditionalActions = map[interface{}]func() resource.Action{PostCollection: resource.CreateAction, PatchInstance: resource.UpdateAction, DeleteInstance: resource.DeleteAction}
+
+func conditional(method string, path string, ifMatch string, content string) *http.Request {
+	header := http.Header{ContentTypeHeader: {JsonContentType}}
+	if ifMatch != "" {
+		header.Set(IfMatchHeader, ifMatch)
+	}
+	return &http.Request{Method: method, URL: &url.URL{Path: path}, Header: header, Body: body(content)}
+}
+
+func TestETagFromVersion(t *testing.T) {
+	store.Clear()
+
+	_, ge := resource.Register(&Article{}, nil, conditionalActions, store, nil)
+	assert.Success(t, ge)
+
+	r, ge := BindFromRequest(conditional(http.MethodPost, "/articles/a1", "", `{"Title": "Draft"}`), reflect.TypeOf(&Article{}), subject, "create")
+	assert.Success(t, ge)
+	r, ge = r.DoAction(resource.CreateAction())
+	assert.Success(t, ge)
+
+	etag, ge := ETag(reflect.ValueOf(r).Elem())
+	assert.Success(t, ge)
+	assert.Equals(t, `"1"`, etag)
+	assert.Assert(t, NotModified(&http.Request{Header: http.Header{IfNoneMatchHeader: {`"0", W/"1"`}}}, etag), "Expected a weak match")
+
+	r, ge = BindFromRequest(conditional(http.MethodPatch, "/articles/a1", `"1"`, `{"Title": "Final"}`), reflect.TypeOf(&Article{}), subject, "update")
+	assert.Success(t, ge)
+	r, ge = r.DoAction(resource.UpdateAction())
+	assert.Success(t, ge)
+	assert.Equals(t, 2, r.(*Article).Revision)
+
+	// The client's version is now stale
+	r, ge = BindFromRequest(conditional(http.MethodPatch, "/articles/a1", `"1"`, `{"Title": "Lost"}`), reflect.TypeOf(&Article{}), subject, "update")
+	assert.Success(t, ge)
+	_, ge = r.DoAction(resource.UpdateAction())
+	assert.ErrorType(t, ge, &PreconditionFailedError{}, "Expected a stale If-Match to fail")
+
+	r, ge = BindFromRequest(conditional(http.MethodDelete, "/articles/a1", `"1"`, ""), reflect.TypeOf(&Article{}), subject, "delete")
+	assert.Success(t, ge)
+	_, ge = r.DoAction(resource.DeleteAction())
+	assert.ErrorType(t, ge, &PreconditionFailedError{}, "Expected a stale If-Match to fail")
+
+	versionMismatch := dataerr.VersionMismatch("Article", 2)
+	assert.ErrorType(t, PreconditionFailedIfVersionChanged(conditional(http.MethodDelete, "/articles/a1", `"2"`, ""), versionMismatch), &PreconditionFailedError{}, "Expected a version mismatch to fail the precondition")
+
+	r, ge = BindFromRequest(conditional(http.MethodDelete, "/articles/a1", `"2"`, ""), reflect.TypeOf(&Article{}), subject, "delete")
+	assert.Success(t, ge)
+	_, ge = r.DoAction(resource.DeleteAction())
+	assert.Success(t, ge)
+}
+
+func TestETagFromContent(t *testing.T) {
+	store.Clear()
+
+	_, ge := resource.Register(&Note{}, nil, conditionalActions, store, nil)
+	assert.Success(t, ge)
+
+	r, ge := BindFromRequest(conditional(http.MethodPost, "/notes/n1", "", `{"Text": "Hello"}`), reflect.TypeOf(&Note{}), subject, "create")
+	assert.Success(t, ge)
+	r, ge = r.DoAction(resource.CreateAction())
+	assert.Success(t, ge)
+
+	etag, ge := ETag(reflect.ValueOf(r).Elem())
+	assert.Success(t, ge)
+	assert.Assert(t, len(etag) > 2 && etag[0] == '"', "Expected a quoted entity tag")
+
+	r, ge = BindFromRequest(conditional(http.MethodPatch, "/notes/n1", `"stale"`, `{"Text": "Bye"}`), reflect.TypeOf(&Note{}), subject, "update")
+	assert.Success(t, ge)
+	_, ge = r.DoAction(resource.UpdateAction())
+	assert.ErrorType(t, ge, &PreconditionFailedError{}, "Expected a mismatched If-Match to fail")
+
+	r, ge = BindFromRequest(conditional(http.MethodPatch, "/notes/n1", etag, `{"Text": "Bye"}`), reflect.TypeOf(&Note{}), subject, "update")
+	assert.Success(t, ge)
+	r, ge = r.DoAction(resource.UpdateAction())
+	assert.Success(t, ge)
+
+	updatedETag, ge := ETag(reflect.ValueOf(r).Elem())
+	assert.Success(t, ge)
+	assert.Assert(t, updatedETag != etag, "Expected the entity tag to change with the content")
+}
@@ -0,0 +1,136 @@
+package http
+
+import (
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+
+	bind2 "github.com/jt0/gomer/bind"
+	"github.com/jt0/gomer/gomerr"
+	"github.com/jt0/gomer/limit"
+	"github.com/jt0/gomer/structs"
+)
+
+// File is a file uploaded in a multipart/form-data request and bound with a 'file.<name>' directive. A field can be
+// of type File, *File, or []*File (for more than one file with the same name).
+//
+// Files larger than BindFromRequestConfiguration.MaxMultipartMemory are stored in temporary files until the request
+// has been handled. The routes built by api/gin delete them once the response has been written. Other applications
+// should call RemoveUploadedFiles (e.g. after the action has completed) to do so.
+type File struct {
+	Filename    string
+	ContentType string
+	Size        int64
+
+	header *multipart.FileHeader
+}
+
+// Open returns a reader for the file's content. The caller is responsible for closing it.
+func (f *File) Open() (multipart.File, gomerr.Gomerr) {
+	if f.header == nil {
+		return nil, gomerr.Unprocessable("File has no content", f.Filename)
+	}
+
+	file, err := f.header.Open()
+	if err != nil {
+		return nil, gomerr.Internal("Unable to open uploaded file").AddAttribute("Filename", f.Filename).Wrap(err)
+	}
+
+	return file, nil
+}
+
+// RemoveUploadedFiles deletes any temporary files created while parsing the request's multipart form.
+func RemoveUploadedFiles(request *http.Request) {
+	if request.MultipartForm != nil {
+		_ = request.MultipartForm.RemoveAll()
+	}
+}
+
+var (
+	fileType       = reflect.TypeOf(File{})
+	filePtrType    = reflect.TypeOf(&File{})
+	filePtrsType   = reflect.TypeOf([]*File{})
+	hasFormBinding = make(map[string]bool)
+)
+
+// bindForm parses a form-urlencoded or multipart/form-data body so that it can be bound with 'form.' and 'file.'
+// directives. The form's values are also available to payload directives as with other content types.
+func bindForm(request *http.Request, contentType string, tc *structs.ToolContext) gomerr.Gomerr {
+	var values url.Values
+	var files map[string][]*multipart.FileHeader
+
+	var err error
+	if contentType == MultipartFormContentType {
+		if err = request.ParseMultipartForm(requestConfig.MaxMultipartMemory); err == nil {
+			values, files = request.MultipartForm.Value, request.MultipartForm.File
+		}
+	} else if err = request.ParseForm(); err == nil {
+		values = request.PostForm
+	}
+
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			return limit.Exceeded("BindFromRequest", "body", limit.DataSize(requestConfig.MaxBodySize), limit.Unknown, limit.Unknown).Wrap(err)
+		}
+		return gomerr.Unmarshal("Unable to parse form data", nil, nil).AddAttribute("ContentType", contentType).Wrap(err)
+	}
+
+	unmarshaled := make(map[string]interface{}, len(values))
+	addFormValuesToMap(unmarshaled, values)
+	tc.Put(formValuesKey, values).Put(formFilesKey, files).Put(bind2.InKey, unmarshaled)
+
+	return nil
+}
+
+type bindFormApplier struct {
+	name string
+}
+
+func (b bindFormApplier) Apply(_ reflect.Value, fv reflect.Value, tc *structs.ToolContext) gomerr.Gomerr {
+	formValues, _ := tc.Get(formValuesKey).(url.Values) // not present unless the request has form data
+	values := formValues[b.name]
+	if len(values) == 0 {
+		return nil
+	}
+	bind2.MarkInputUsed(strings.SplitN(b.name, formKeySeparator, 2)[0], tc) // form values are also payload input (see bindForm)
+
+	if ge := setValues(fv, values); ge != nil {
+		return ge.AddAttributes("FormField", b.name)
+	}
+
+	return nil
+}
+
+type bindFileApplier struct {
+	name string
+}
+
+func (b bindFileApplier) Apply(_ reflect.Value, fv reflect.Value, tc *structs.ToolContext) gomerr.Gomerr {
+	formFiles, _ := tc.Get(formFilesKey).(map[string][]*multipart.FileHeader) // not present unless the request has form data
+	headers := formFiles[b.name]
+	if len(headers) == 0 {
+		return nil
+	}
+
+	files := make([]*File, len(headers))
+	for i, header := range headers {
+		files[i] = &File{Filename: header.Filename, ContentType: header.Header.Get(ContentTypeHeader), Size: header.Size, header: header}
+	}
+
+	switch fv.Type() {
+	case fileType:
+		fv.Set(reflect.ValueOf(*files[0]))
+	case filePtrType:
+		fv.Set(reflect.ValueOf(files[0]))
+	case filePtrsType:
+		fv.Set(reflect.ValueOf(files))
+	default:
+		return gomerr.Configuration("File field must be of type http.File, *http.File, or []*http.File, not: "+fv.Type().String()).AddAttribute("FileField", b.name)
+	}
+
+	return nil
+}
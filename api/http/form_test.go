@@ -0,0 +1,96 @@
+package http_test
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/jt0/gomer/_test/assert"
+	"github.com/jt0/gomer/_test/helpers/stores"
+	. "github.com/jt0/gomer/api/http"
+	"github.com/jt0/gomer/resource"
+)
+
+type Document struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	Title       string   `in:"form.title"`
+	Tags        []string `in:"form.tag"`
+	Content     *File    `in:"file.content"`
+	Attachments []*File  `in:"file.attachment"`
+}
+
+func TestBindMultipartForm(t *testing.T) {
+	_, ge := resource.Register(&Document{}, nil, actions, stores.PanicStore, nil)
+	assert.Success(t, ge)
+
+	var buffer bytes.Buffer
+	writer := multipart.NewWriter(&buffer)
+	assert.Success(t, writer.WriteField("title", "Report"))
+	assert.Success(t, writer.WriteField("tag", "a"))
+	assert.Success(t, writer.WriteField("tag", "b"))
+	for name, content := range map[string]string{"content": "main", "attachment": "extra"} {
+		part, err := writer.CreateFormFile(name, name+".txt")
+		assert.Success(t, err)
+		_, err = part.Write([]byte(content))
+		assert.Success(t, err)
+	}
+	assert.Success(t, writer.Close())
+
+	request := &http.Request{
+		Method: "POST",
+		URL:    &url.URL{Path: "/"},
+		Header: http.Header{ContentTypeHeader: []string{writer.FormDataContentType()}},
+		Body:   io.NopCloser(&buffer),
+	}
+	defer RemoveUploadedFiles(request)
+
+	r, ge := BindFromRequest(request, reflect.TypeOf(&Document{}), subject, "some_scope")
+	assert.Success(t, ge)
+
+	document := r.(*Document)
+	assert.Equals(t, "Report", document.Title)
+	assert.Equals(t, []string{"a", "b"}, document.Tags)
+	assert.Equals(t, "content.txt", document.Content.Filename)
+	assert.Equals(t, int64(4), document.Content.Size)
+	assert.Equals(t, 1, len(document.Attachments))
+
+	file, ge := document.Content.Open()
+	assert.Success(t, ge)
+	content, _ := io.ReadAll(file)
+	assert.Equals(t, "main", string(content))
+
+	request = &http.Request{
+		Method: "POST",
+		URL:    &url.URL{Path: "/"},
+		Header: http.Header{ContentTypeHeader: []string{FormContentType}},
+		Body:   body("title=Memo&tag=c"),
+	}
+	r, ge = BindFromRequest(request, reflect.TypeOf(&Document{}), subject, "some_scope")
+	assert.Success(t, ge)
+	assert.Equals(t, "Memo", r.(*Document).Title)
+	assert.Equals(t, []string{"c"}, r.(*Document).Tags)
+	assert.Assert(t, r.(*Document).Content == nil, "Expected no file")
+}
+
+type Memo struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	Title string `in:"form.title"`
+	Raw   []byte `in:"body"`
+}
+
+func TestBindFormWithBodyBinding(t *testing.T) {
+	_, ge := resource.Register(&Memo{}, nil, actions, stores.PanicStore, nil)
+	assert.Success(t, ge)
+
+	request := &http.Request{Method: "POST", URL: &url.URL{Path: "/"}, Header: http.Header{ContentTypeHeader: {FormContentType}}, Body: body("title=Hi")}
+	r, ge := BindFromRequest(request, reflect.TypeOf(&Memo{}), subject, "some_scope")
+	assert.Success(t, ge)
+	assert.Equals(t, "Hi", r.(*Memo).Title)
+	assert.Equals(t, 0, len(r.(*Memo).Raw)) // the form's body is parsed rather than bound
+}
@@ -1,6 +1,8 @@
 package http
 
 import (
+	"net/http"
+
 	"github.com/jt0/gomer/resource"
 )
 
@@ -37,6 +39,10 @@ func (o Op) IsBuiltIn() bool {
 	return o&creatorTypeMask == builtIn
 }
 
+func (o Op) String() string {
+	return o.Method() + " " + string(o.ResourceType())
+}
+
 type Method = Op
 
 const (
@@ -123,3 +129,42 @@ const (
 	HeadInstance    = Head + instance
 	OptionsInstance = Options + instance
 )
+
+// CustomOp is an application-defined Op that is exposed under a named sub-path of the instance or collection path it
+// applies to (e.g. 'POST /orders/:OrderId/cancel'). When routes are built, Name is also registered as a scope alias
+// for the associated action so that it can be used to scope struct tag directives (e.g. `in:"cancel:+"`). Use
+// NewCustomOp to construct one.
+type CustomOp struct {
+	Op
+	Name string
+
+	successStatusCode int
+}
+
+// NewCustomOp returns a CustomOp for the given method, category and name. The default success status code is 204 (No
+// Content) for DELETE and 200 (OK) for all other methods.
+func NewCustomOp(method Method, category resource.Category, name string) CustomOp {
+	return CustomOp{Op: NewOp(method, category), Name: name}
+}
+
+// WithSuccessStatusCode returns a copy of the CustomOp that will respond with statusCode when its action succeeds.
+func (c CustomOp) WithSuccessStatusCode(statusCode int) CustomOp {
+	c.successStatusCode = statusCode
+	return c
+}
+
+func (c CustomOp) String() string {
+	return c.Op.String() + " /" + c.Name
+}
+
+func (c CustomOp) SuccessStatusCode() int {
+	if c.successStatusCode != 0 {
+		return c.successStatusCode
+	}
+
+	if c.Op&methodMask == Delete_ {
+		return http.StatusNoContent
+	}
+
+	return http.StatusOK
+}
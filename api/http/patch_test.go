@@ -0,0 +1,124 @@
+package http_test
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/jt0/gomer/_test/assert"
+	. "github.com/jt0/gomer/api/http"
+	"github.com/jt0/gomer/data"
+	"github.com/jt0/gomer/gomerr"
+	"github.com/jt0/gomer/resource"
+)
+
+type Gadget struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	GadgetId string `in:"path.1" id:"+"`
+	Name     string `in:"+"`
+	Enabled  bool   `in:"+"`
+	Count    int    `in:"+"`
+}
+
+func TestPatch(t *testing.T) {
+	store.Clear()
+
+	patchActions := map[interface{}]func() resource.Action{PostCollection: resource.CreateAction, GetInstance: resource.ReadAction, PatchInstance: resource.UpdateAction}
+	_, ge := resource.Register(&Gadget{}, nil, patchActions, store, nil)
+	assert.Success(t, ge)
+
+	r, ge := resource.New(reflect.TypeOf(&Gadget{}), subject)
+	assert.Success(t, ge)
+	gadget := r.(*Gadget)
+	gadget.GadgetId, gadget.Name, gadget.Enabled, gadget.Count = "g1", "Gizmo", true, 3
+	_, ge = gadget.DoAction(resource.CreateAction())
+	assert.Success(t, ge)
+
+	patch := func(contentType string, content string) *Gadget {
+		request := &http.Request{Method: http.MethodPatch, URL: &url.URL{Path: "/gadgets/g1"}, Header: http.Header{ContentTypeHeader: {contentType}}, Body: body(content)}
+		update, ge := BindFromRequest(request, reflect.TypeOf(&Gadget{}), subject, "update")
+		assert.Success(t, ge)
+
+		updated, ge := update.DoAction(resource.UpdateAction())
+		assert.Success(t, ge)
+		return updated.(*Gadget)
+	}
+
+	updated := patch(MergePatchContentType, `{"Enabled": false, "Name": null}`)
+	assert.Equals(t, Gadget{GadgetId: "g1", Count: 3}, Gadget{GadgetId: updated.GadgetId, Name: updated.Name, Enabled: updated.Enabled, Count: updated.Count})
+
+	updated = patch(JsonPatchContentType, `[{"op": "add", "path": "/Name", "value": "Widget"}, {"op": "remove", "path": "/Count"}]`)
+	assert.Equals(t, Gadget{GadgetId: "g1", Name: "Widget"}, Gadget{GadgetId: updated.GadgetId, Name: updated.Name, Enabled: updated.Enabled, Count: updated.Count})
+
+	request := &http.Request{Method: http.MethodPatch, URL: &url.URL{Path: "/gadgets/g1"}, Header: http.Header{ContentTypeHeader: {JsonPatchContentType}}, Body: body(`[{"op": "move", "from": "/Name", "path": "/Id"}]`)}
+	_, ge = BindFromRequest(request, reflect.TypeOf(&Gadget{}), subject, "update")
+	assert.ErrorType(t, ge, &gomerr.UnmarshalError{}, "Expected an unsupported operation to be rejected")
+}
+
+func TestJsonPatch(t *testing.T) {
+	store.Clear()
+
+	patchActions := map[interface{}]func() resource.Action{PostCollection: resource.CreateAction, GetInstance: resource.ReadAction, PatchInstance: resource.UpdateAction}
+	_, ge := resource.Register(&Gadget{}, nil, patchActions, store, nil)
+	assert.Success(t, ge)
+
+	r, ge := resource.New(reflect.TypeOf(&Gadget{}), subject)
+	assert.Success(t, ge)
+	gadget := r.(*Gadget)
+	gadget.GadgetId, gadget.Name = "g1", "Gizmo"
+	_, ge = gadget.DoAction(resource.CreateAction())
+	assert.Success(t, ge)
+
+	patch := func(content string) (resource.Resource, gomerr.Gomerr) {
+		request := &http.Request{Method: http.MethodPatch, URL: &url.URL{Path: "/gadgets/g1"}, Header: http.Header{ContentTypeHeader: {JsonPatchContentType}}, Body: body(content)}
+		update, ge := BindFromRequest(request, reflect.TypeOf(&Gadget{}), subject, "update")
+		if ge != nil {
+			return nil, ge
+		}
+		return update.DoAction(resource.UpdateAction())
+	}
+
+	t.Run("NullIsNotRemove", func(t *testing.T) {
+		request := &http.Request{Method: http.MethodPatch, URL: &url.URL{Path: "/gadgets/g1"}, Header: http.Header{ContentTypeHeader: {JsonPatchContentType}}, Body: body(`[{"op": "add", "path": "/Name", "value": null}, {"op": "remove", "path": "/Count"}]`)}
+		update, ge := BindFromRequest(request, reflect.TypeOf(&Gadget{}), subject, "update")
+		assert.Success(t, ge)
+		changes := update.(data.ExplicitUpdate).UpdateChanges()
+		assert.Equals(t, data.Changes{Set: map[string]bool{"Name": true}, Removed: map[string]bool{"Count": true}}, *changes)
+	})
+
+	t.Run("ReplaceExistingMember", func(t *testing.T) {
+		updated, ge := patch(`[{"op": "replace", "path": "/Name", "value": "Widget"}]`)
+		assert.Success(t, ge)
+		assert.Equals(t, "Widget", updated.(*Gadget).Name)
+	})
+
+	t.Run("ReplaceAbsentMember", func(t *testing.T) {
+		_, ge := patch(`[{"op": "replace", "path": "/Count", "value": 4}]`)
+		assert.ErrorType(t, ge, &gomerr.BadValueError{}, "Expected a replace of a stored member without a value to be rejected")
+	})
+
+	t.Run("ReplaceRemovedMember", func(t *testing.T) {
+		_, ge := patch(`[{"op": "remove", "path": "/Name"}, {"op": "replace", "path": "/Name", "value": "Widget"}]`)
+		assert.ErrorType(t, ge, &gomerr.UnmarshalError{}, "Expected a replace of a member removed by the patch to be rejected")
+	})
+
+	t.Run("ReplaceAddedMember", func(t *testing.T) {
+		updated, ge := patch(`[{"op": "add", "path": "/Count", "value": 4}, {"op": "replace", "path": "/Count", "value": 5}]`)
+		assert.Success(t, ge)
+		assert.Equals(t, 5, updated.(*Gadget).Count)
+	})
+
+	t.Run("ReplaceUnknownMember", func(t *testing.T) {
+		_, ge := patch(`[{"op": "replace", "path": "/Color", "value": "red"}]`)
+		assert.ErrorType(t, ge, &gomerr.BadValueError{}, "Expected a replace of a member that isn't a field to be rejected")
+	})
+
+	r, ge = resource.New(reflect.TypeOf(&Gadget{}), subject)
+	assert.Success(t, ge)
+	stored := r.(*Gadget)
+	stored.GadgetId = "g1"
+	assert.Success(t, store.ReadFull(stored))
+	assert.Equals(t, Gadget{GadgetId: "g1", Name: "Widget", Count: 5}, Gadget{GadgetId: stored.GadgetId, Name: stored.Name, Enabled: stored.Enabled, Count: stored.Count})
+}
@@ -0,0 +1,61 @@
+package http
+
+import (
+	"strings"
+
+	"github.com/jt0/gomer/structs"
+)
+
+// AddPathParamsToContext adds the named path parameters matched by a router (e.g. gin's c.Params) so that they can be
+// bound with 'path.<name>' directives.
+func AddPathParamsToContext(params map[string]string, tcs ...*structs.ToolContext) *structs.ToolContext {
+	return structs.EnsureContext(tcs...).Put(PathParamsKey, params)
+}
+
+// MatchRouteTemplate returns the path parameters if the path matches the route template. A template segment of the
+// form ':<name>' or '{<name>}' matches any single (non-empty) path segment, and a final segment of the form '*<name>'
+// matches the rest of the path. Other segments must match exactly. Leading and trailing slashes are ignored.
+//
+// For routers that don't provide their matched parameters, the result can be passed to AddPathParamsToContext.
+func MatchRouteTemplate(template string, path string) (map[string]string, bool) {
+	templateParts := strings.Split(strings.Trim(template, "/"), "/")
+	pathParts := strings.Split(strings.Trim(path, "/"), "/")
+
+	params := make(map[string]string)
+	for i, templatePart := range templateParts {
+		if strings.HasPrefix(templatePart, "*") && i == len(templateParts)-1 {
+			if i < len(pathParts) {
+				params[templatePart[1:]] = strings.Join(pathParts[i:], "/")
+			} else {
+				params[templatePart[1:]] = ""
+			}
+			return params, true
+		}
+
+		if i >= len(pathParts) {
+			return nil, false
+		}
+
+		pathPart := pathParts[i]
+		switch {
+		case strings.HasPrefix(templatePart, ":"):
+			if pathPart == "" {
+				return nil, false
+			}
+			params[templatePart[1:]] = pathPart
+		case strings.HasPrefix(templatePart, "{") && strings.HasSuffix(templatePart, "}"):
+			if pathPart == "" {
+				return nil, false
+			}
+			params[templatePart[1:len(templatePart)-1]] = pathPart
+		case templatePart != pathPart:
+			return nil, false
+		}
+	}
+
+	if len(pathParts) != len(templateParts) {
+		return nil, false
+	}
+
+	return params, true
+}
@@ -0,0 +1,46 @@
+package http_test
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/jt0/gomer/_test/assert"
+	"github.com/jt0/gomer/_test/helpers/stores"
+	. "github.com/jt0/gomer/api/http"
+	"github.com/jt0/gomer/resource"
+)
+
+type Comment struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	PostId    string `in:"path.PostId"`
+	CommentId string `in:"path.+"`
+	Second    string `in:"path.1"` // index binding still works
+}
+
+func TestBindNamedPathParams(t *testing.T) {
+	_, ge := resource.Register(&Comment{}, nil, actions, stores.PanicStore, nil)
+	assert.Success(t, ge)
+
+	path := "/v1/posts/p1/comments/c1"
+	params, ok := MatchRouteTemplate("/v1/posts/:PostId/comments/{CommentId}", path)
+	assert.Assert(t, ok, "Expected path to match template")
+
+	request := &http.Request{URL: &url.URL{Path: path}, Body: body("")}
+	r, ge := BindFromRequest(request, reflect.TypeOf(&Comment{}), subject, "some_scope", AddPathParamsToContext(params))
+	assert.Success(t, ge)
+
+	comment := r.(*Comment)
+	assert.Equals(t, "p1", comment.PostId)
+	assert.Equals(t, "c1", comment.CommentId)
+	assert.Equals(t, "posts", comment.Second)
+
+	_, ok = MatchRouteTemplate("/v1/posts/:PostId", path)
+	assert.Assert(t, !ok, "Expected longer path not to match")
+
+	params, ok = MatchRouteTemplate("/files/*Path", "/files/a/b.txt")
+	assert.Assert(t, ok, "Expected catch-all to match")
+	assert.Equals(t, "a/b.txt", params["Path"])
+}
@@ -0,0 +1,77 @@
+package http_test
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/jt0/gomer/_test/assert"
+	"github.com/jt0/gomer/_test/helpers/stores"
+	. "github.com/jt0/gomer/api/http"
+	"github.com/jt0/gomer/resource"
+)
+
+type StatusFilter struct {
+	Status string
+	Limit  int
+}
+
+type Search struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	Tags   []string          `in:"query.tag"`
+	Ids    []int             `in:"query.ids"`
+	Filter StatusFilter      `in:"query.filter"`
+	Labels map[string]string `in:"query.label"`
+	Sort   string            `in:"query.sort"`
+	Locale []string          `in:"header.Accept-Language"`
+}
+
+func TestBindMultiValuedParams(t *testing.T) {
+	_, ge := resource.Register(&Search{}, nil, actions, stores.PanicStore, nil)
+	assert.Success(t, ge)
+
+	request := &http.Request{
+		URL:    &url.URL{RawQuery: "tag=a&tag=b,c&ids=1,2&filter[status]=open&filter[limit]=5&label[env]=prod"},
+		Header: http.Header{"Accept-Language": []string{"en", "fr"}},
+		Body:   body(""),
+	}
+	r, ge := BindFromRequest(request, reflect.TypeOf(&Search{}), subject, "some_scope")
+	assert.Success(t, ge)
+
+	search := r.(*Search)
+	assert.Equals(t, []string{"a", "b", "c"}, search.Tags)
+	assert.Equals(t, []int{1, 2}, search.Ids)
+	assert.Equals(t, StatusFilter{Status: "open", Limit: 5}, search.Filter)
+	assert.Equals(t, map[string]string{"env": "prod"}, search.Labels)
+	assert.Equals(t, []string{"en", "fr"}, search.Locale)
+
+	request = &http.Request{URL: &url.URL{RawQuery: "ids=1,x"}, Body: body("")}
+	_, ge = BindFromRequest(request, reflect.TypeOf(&Search{}), subject, "some_scope")
+	assert.Error(t, ge, "Expected a non-numeric id to fail")
+}
+
+func TestCaseInsensitiveQueryParams(t *testing.T) {
+	_, ge := resource.Register(&Search{}, nil, actions, stores.PanicStore, nil)
+	assert.Success(t, ge)
+
+	config := NewBindFromRequestConfiguration()
+	config.CaseInsensitiveQueryParams = true
+	SetBindFromRequestConfiguration(config)
+	defer SetBindFromRequestConfiguration(NewBindFromRequestConfiguration())
+
+	request := &http.Request{URL: &url.URL{RawQuery: "TAG=a&Filter[Status]=open"}, Body: body("")}
+	r, ge := BindFromRequest(request, reflect.TypeOf(&Search{}), subject, "some_scope")
+	assert.Success(t, ge)
+	assert.Equals(t, []string{"a"}, r.(*Search).Tags)
+	assert.Equals(t, "open", r.(*Search).Filter.Status)
+
+	for i := 0; i < 10; i++ { // map iteration order varies, so bind a few times
+		request = &http.Request{URL: &url.URL{RawQuery: "Sort=name&sort=date&TAG=a&tag=b&Tag=c"}, Body: body("")}
+		r, ge = BindFromRequest(request, reflect.TypeOf(&Search{}), subject, "some_scope")
+		assert.Success(t, ge)
+		assert.Equals(t, "date", r.(*Search).Sort)
+		assert.Equals(t, []string{"b", "a", "c"}, r.(*Search).Tags)
+	}
+}
@@ -0,0 +1,55 @@
+package http_test
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/jt0/gomer/_test/assert"
+	"github.com/jt0/gomer/_test/helpers/stores"
+	. "github.com/jt0/gomer/api/http"
+	"github.com/jt0/gomer/bind"
+	"github.com/jt0/gomer/gomerr"
+	"github.com/jt0/gomer/resource"
+	"github.com/jt0/gomer/structs"
+)
+
+type Widget struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	Name  string `in:"+"`
+	Color string `in:"form.color"`
+}
+
+func TestStrictInput(t *testing.T) {
+	_, ge := resource.Register(&Widget{}, nil, actions, stores.PanicStore, nil)
+	assert.Success(t, ge)
+
+	config := NewBindFromRequestConfiguration()
+	config.BindConfiguration = bind.NewConfiguration(bind.StrictInputForScopes("create"))
+	SetBindFromRequestConfiguration(config)
+	defer SetBindFromRequestConfiguration(NewBindFromRequestConfiguration())
+
+	request := func(contentType string, content string) *http.Request {
+		return &http.Request{Method: http.MethodPost, URL: &url.URL{Path: "/"}, Header: http.Header{ContentTypeHeader: {contentType}}, Body: body(content)}
+	}
+
+	_, ge = BindFromRequest(request(JsonContentType, `{"Name": "n", "Size": 3}`), reflect.TypeOf(&Widget{}), subject, "update")
+	assert.Success(t, ge)
+
+	_, ge = BindFromRequest(request(JsonContentType, `{"Name": "n", "Size": 3}`), reflect.TypeOf(&Widget{}), subject, "create")
+	assert.ErrorType(t, ge, &gomerr.BadValueError{}, "Expected an unknown field to be rejected")
+	assert.Equals(t, "Size", ge.(*gomerr.BadValueError).Name)
+
+	r, ge := BindFromRequest(request(FormContentType, "Name=n&color=red"), reflect.TypeOf(&Widget{}), subject, "create")
+	assert.Success(t, ge)
+	assert.Equals(t, "red", r.(*Widget).Color)
+
+	// A streamed body isn't read, but other input data is still verified
+	_, ge = resource.Register(&Upload{}, nil, actions, stores.PanicStore, nil)
+	assert.Success(t, ge)
+	_, ge = BindFromRequest(request(JsonContentType, `{}`), reflect.TypeOf(&Upload{}), subject, "create", structs.EnsureContext().Put(bind.InKey, map[string]interface{}{"Name": "n"}))
+	assert.ErrorType(t, ge, &gomerr.BadValueError{}, "Expected an unknown field to be rejected with a streamed body")
+	assert.Equals(t, "Name", ge.(*gomerr.BadValueError).Name)
+}
@@ -0,0 +1,14 @@
+package http
+
+import (
+	"github.com/jt0/gomer/auth"
+	"github.com/jt0/gomer/constraint"
+	"github.com/jt0/gomer/gomerr"
+	"github.com/jt0/gomer/resource"
+)
+
+// ValidateResources calls resource.Validate with the tools used for binding requests and responses, validating
+// input, and controlling field access. Call it after all resources (and field access principals) are registered.
+func ValidateResources() gomerr.Gomerr {
+	return resource.Validate(DefaultBindFromRequestTool, DefaultBindToResponseTool, constraint.DefaultValidationTool, auth.DefaultAccessTool)
+}
@@ -1,8 +1,8 @@
 package auth
 
 import (
-	"strconv"
-	"unsafe"
+	"strings"
+	"sync"
 
 	"github.com/jt0/gomer/gomerr"
 )
@@ -18,31 +18,86 @@ const (
 // RegisterFieldAccessPrincipals
 //goland:noinspection GoUnusedGlobalVariable
 var (
-	ReadWriteAllFields = AccessPrincipal{ReadWriteAll, fieldAccessPrincipal}
-	ReadAllFields      = AccessPrincipal{ReadAll, fieldAccessPrincipal}
-	NoFieldAccess      = AccessPrincipal{NoAccess, fieldAccessPrincipal}
+	ReadWriteAllFields = AccessPrincipal{name: ReadWriteAll, principalType: fieldAccessPrincipal}
+	ReadAllFields      = AccessPrincipal{name: ReadAll, principalType: fieldAccessPrincipal}
+	NoFieldAccess      = AccessPrincipal{name: NoAccess, principalType: fieldAccessPrincipal}
 )
 
 func NewFieldAccessPrincipal(name string) AccessPrincipal {
+	return NewTenantFieldAccessPrincipal("", name)
+}
+
+// NewTenantFieldAccessPrincipal creates an AccessPrincipal that belongs to the given tenant's principal set. See
+// RegisterTenantFieldAccessPrincipals.
+func NewTenantFieldAccessPrincipal(tenant, name string) AccessPrincipal {
 	if name == ReadWriteAll || name == ReadAll || name == NoAccess {
 		panic("Cannot create an AccessPrincipal with one a predefined name: " + name)
+	} else if tenant == maskKey {
+		panic("Cannot create an AccessPrincipal for a reserved tenant name: " + tenant)
 	}
 
-	return AccessPrincipal{name /* fieldAccessScope, */, fieldAccessPrincipal}
+	return AccessPrincipal{name: name, tenant: tenant /* fieldAccessScope, */, principalType: fieldAccessPrincipal}
 }
 
 // RegisterFieldAccessPrincipals allows the application to define named principals with different levels of access
-// to the fields that comprise that application's domain entities.
+// to the fields that comprise that application's domain entities. The order of the principals is the order of their
+// permission groups in an 'access' mode string.
 func RegisterFieldAccessPrincipals(accessPrincipals ...AccessPrincipal) {
-	l := len(accessPrincipals)
-	if l > maximumRegisteredAccessPrincipals {
-		panic("Too many principals - maximum count = " + strconv.Itoa(maximumRegisteredAccessPrincipals))
+	RegisterTenantFieldAccessPrincipals("", accessPrincipals...)
+}
+
+// FieldAccessPrincipalsFromClaim returns the registered field access principals for the tenant ("" for the default
+// set) named by a claim's value, e.g. a JWT's "roles" claim. The value may be a string of space or comma-separated
+// names, or a slice of names. Unrecognized names are ignored. A SubjectProvider can add the result to a Subject created
+// with NewSubject via its AddPrincipals method.
+func FieldAccessPrincipalsFromClaim(claimValue interface{}, tenant string) []Principal {
+	var names []string
+	switch v := claimValue.(type) {
+	case string:
+		names = strings.FieldsFunc(v, func(r rune) bool { return r == ' ' || r == ',' })
+	case []string:
+		names = v
+	case []interface{}:
+		for _, name := range v {
+			if s, ok := name.(string); ok {
+				names = append(names, s)
+			}
+		}
+	}
+
+	principals := make([]Principal, 0, len(names))
+	for _, name := range names {
+		for principal := range fieldAccessPrincipalSets[tenant] {
+			if principal.name == name {
+				principals = append(principals, principal)
+				break
+			}
+		}
+	}
+
+	return principals
+}
+
+// RegisterTenantFieldAccessPrincipals defines a tenant-specific set of principals. Each must have been created with
+// NewTenantFieldAccessPrincipal for the same tenant. A field's permissions for a tenant's principals are given in the
+// 'access' directive after the default ones, e.g. `access:"rwr-|acme=rwrcr-"`. If a field has no permissions for the
+// tenant, the default ones are used so long as the tenant has the same number of principals as the default set.
+// Otherwise, the tenant's principals are denied access to the field. The tenant name "mask" is reserved for a field's
+// mask (see NewAccessTool).
+func RegisterTenantFieldAccessPrincipals(tenant string, accessPrincipals ...AccessPrincipal) {
+	if tenant == maskKey {
+		panic("Cannot register AccessPrincipals for a reserved tenant name: " + tenant)
 	}
 
-	l--
-	for i, r := range accessPrincipals {
-		fieldAccessPrincipalIndexes[r] = uint(l - i)
+	indexes := make(map[AccessPrincipal]int, len(accessPrincipals))
+	for i, p := range accessPrincipals {
+		if p.tenant != tenant {
+			panic("AccessPrincipal '" + p.name + "' does not belong to tenant '" + tenant + "'")
+		}
+		indexes[p] = i
 	}
+
+	fieldAccessPrincipalSets[tenant] = indexes
 }
 
 // At some point we may want to support additional access scopes, but for now, accessScope and fieldAccessScope
@@ -58,12 +113,14 @@ const (
 
 type accessScope = PrincipalType
 
-var fieldAccessPrincipalIndexes = make(map[Principal]uint)
+// fieldAccessPrincipalSets maps a tenant ("" for the default set) to its principals' positions in a mode string.
+var fieldAccessPrincipalSets = map[string]map[AccessPrincipal]int{"": {}}
 
 // AccessPrincipal corresponds to a named Principal that can be associated with permissions to grant CRUD (extensible
 // to others) abilities on different things w/in an application.
 type AccessPrincipal struct {
-	name string
+	name   string
+	tenant string
 	// scope         accessScope
 	principalType PrincipalType
 }
@@ -89,50 +146,72 @@ const (
 	reserved3                                      // = 0b00000100 (4)
 	CreatePermission                               // = 0b00001000 (8)
 	UpdatePermission                               // = 0b00010000 (16)
-	deletePermission                               // = 0b00100000 (32) NB: until exported, this need not be for 'delete'
+	DeletePermission                               // = 0b00100000 (32)
 	reserved7                                      // = 0b01000000 (64)
 	reserved8                                      // = 0b10000000 (128)
 
 	WritePermissions     = CreatePermission | UpdatePermission
-	LifecyclePermissions = WritePermissions | deletePermission
+	LifecyclePermissions = WritePermissions | DeletePermission
 	NoPermissions        = 0b00000000
 	AllPermissions       = ^NoPermissions // 0b11111111
+)
 
-	sizeOfAccessPermissions          = unsafe.Sizeof(principalPermissions(0))
-	sizeOfPrincipalAccessPermissions = unsafe.Sizeof(ReadPermission)
-
-	maximumRegisteredAccessPrincipals = int(sizeOfAccessPermissions / sizeOfPrincipalAccessPermissions)
+// The reserved bits are available for application-defined permissions. See RegisterCustomPermission.
+var (
+	availableCustomPermissions = []AccessPermissions{reserved2, reserved3, reserved7, reserved8}
+	customPermissionChars      = make(map[rune]AccessPermissions)
 
-	bitsPerByte             = 8
-	permissionsPerPrincipal = uint(sizeOfPrincipalAccessPermissions) * bitsPerByte
+	// accessRegexpLock guards accessRegexp, which may only be rebuilt until it's first used to parse a directive.
+	accessRegexpLock sync.Mutex
+	accessRegexpUsed bool
 )
 
-type principalPermissions uint32
-
-func (p principalPermissions) grants(principal AccessPrincipal, permissionsNeeded AccessPermissions) bool {
-	if permissionsNeeded == NoPermissions {
-		return false // TODO: should this return true or false?
+// RegisterCustomPermission defines an application-specific permission (e.g. to approve or publish a resource) and
+// the character used for it in 'access' mode strings. Custom permissions (and delete) follow a principal's read and
+// write characters, so with 'a' registered for an "approve" permission, "rwdar-" grants the first principal read,
+// write, delete, and approve permissions and the second one only read. A custom resource.Action can require the
+// returned permission from its FieldAccessPermissions method. Up to four custom permissions may be registered.
+// Registering a character again returns the permission it was first registered with.
+//
+// Custom permissions must be registered before the first 'access' directive is parsed (e.g. in an init function or
+// a package-level variable's initializer) since previously parsed types would not recognize the new character.
+// Registering a new character after that panics.
+func RegisterCustomPermission(char rune) AccessPermissions {
+	accessRegexpLock.Lock()
+	defer accessRegexpLock.Unlock()
+
+	if strings.ContainsRune(string([]rune{ReadChar, MaskedReadChar, WriteChar, CreateChar, UpdateChar, ProvidedChar, DenyChar, DeleteChar}), char) {
+		panic("Cannot register a custom permission with a predefined character: " + string(char))
+	} else if permission, ok := customPermissionChars[char]; ok {
+		return permission
+	} else if len(availableCustomPermissions) == 0 {
+		panic("Too many custom permissions - maximum count = 4")
+	} else if accessRegexpUsed {
+		panic("Cannot register a custom permission after an 'access' directive has been parsed: " + string(char))
 	}
 
-	switch principal.name {
-	case NoAccess:
-		return false
-	case ReadWriteAll:
-		return true
-	case ReadAll:
-		return ReadPermission&permissionsNeeded == ReadPermission
-	default:
-		return p.principalAccessPermissions(principal)&permissionsNeeded == permissionsNeeded
+	permission := availableCustomPermissions[0]
+	availableCustomPermissions = availableCustomPermissions[1:]
+	customPermissionChars[char] = permission
+
+	extraChars := string(DeleteChar)
+	for c := range customPermissionChars {
+		extraChars += string(c)
 	}
+	accessRegexp = buildAccessRegexp(extraChars)
+
+	return permission
 }
 
+// principalPermissions holds a field's AccessPermissions for each principal in a set, in the order the principals
+// were registered.
+type principalPermissions []AccessPermissions
+
 func (p principalPermissions) principalAccessPermissions(principal AccessPrincipal) AccessPermissions {
-	principalIndex, ok := fieldAccessPrincipalIndexes[principal]
-	if !ok {
+	principalIndex, ok := fieldAccessPrincipalSets[principal.tenant][principal]
+	if !ok || principalIndex >= len(p) {
 		return NoPermissions
 	}
 
-	// Move the principal's permissions to the lowest-order bits, then convert to the right type which will
-	// truncate the rest.
-	return AccessPermissions(p >> (permissionsPerPrincipal * principalIndex))
+	return p[principalIndex]
 }
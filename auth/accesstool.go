@@ -5,24 +5,30 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/jt0/gomer/data"
 	"github.com/jt0/gomer/gomerr"
 	"github.com/jt0/gomer/structs"
 )
 
 const (
-	ReadChar     = 'r' // ReadPermission
-	WriteChar    = 'w' // CreatePermission | UpdatePermission
-	CreateChar   = 'c' // CreatePermission
-	UpdateChar   = 'u' // UpdatePermission
-	ProvidedChar = 'p' // Provided (field's value is provided by and should be ignored)
-	DenyChar     = '-' // No access
+	ReadChar       = 'r' // ReadPermission
+	MaskedReadChar = 'm' // Read a masked version of the value (see 'mask')
+	WriteChar      = 'w' // CreatePermission | UpdatePermission
+	CreateChar     = 'c' // CreatePermission
+	UpdateChar     = 'u' // UpdatePermission
+	ProvidedChar   = 'p' // Provided (field's value is provided by and should be ignored)
+	DenyChar       = '-' // No access
+	DeleteChar     = 'd' // DeletePermission (optional, follows the write character)
+
+	tenantSeparator     = "|" // Separates the default permissions from each tenant's, e.g. "rwr-|acme=rwrcr-"
+	tenantModeSeparator = "="
 )
 
 var (
 	DefaultAccessTool = NewAccessTool(structs.StructTagDirectiveProvider{"access"})
 
-	accessRegexp = regexp.MustCompile("(r|-)(w|c|u|p|-)")
-	accessGroups = []string{"", "read", "write"}
+	accessRegexp = buildAccessRegexp(string(DeleteChar))
+	accessGroups = []string{"", "read", "write", "extra"}
 )
 
 // NewAccessTool provides a tool is used to validate that a principal performing an action against the fields of a
@@ -49,15 +55,124 @@ var (
 // We don't currently see a use case for allowing some principals to treat an attribute as provided and others not to.
 // To keep the door open for this, though, we require that to specify a field is provided, the 'p' must be set in the
 // leftmost permissions group's write location, and the other permission groups set their write permission value to '-'.
+//
+// Each group may be followed by a 'd' to grant delete permission and by the characters of any custom permissions
+// (see RegisterCustomPermission). These mostly make sense for a type as a whole, which is expressed by putting the
+// 'access' directive on an embedded struct (e.g. an embedded resource.BaseInstance with `access:"rwdr-"`). See
+// TypeAccessGranted.
+//
+// A field's value can be shown in masked form to principals that can't read it in the clear by using 'm' in place of
+// 'r' and (optionally) adding a mask after the mode, e.g. "rwm-|mask=last4". Supported masks are 'last4' (all but the
+// last four characters are replaced with '*'), 'hash' (a truncated HMAC-SHA256 of the value using the key set with
+// SetHashMaskKey), and 'placeholder(<text>)'. If no mask is specified, the value is replaced with "****". Masking is
+// only supported for string fields.
 func NewAccessTool(dp structs.DirectiveProvider) *structs.Tool {
 	return structs.NewTool(accessToolType, accessApplierProvider{}, dp)
 }
 
 type accessApplierProvider struct{}
 
-func (ap accessApplierProvider) Applier(_ reflect.Type, sf reflect.StructField, directive string, _ string) (structs.Applier, gomerr.Gomerr) {
+func (ap accessApplierProvider) Applier(st reflect.Type, sf reflect.StructField, directive string, _ string) (structs.Applier, gomerr.Gomerr) {
+	fa, ge := parseDirective(directive)
+	if ge != nil {
+		return nil, ge
+	}
+
+	// An embedded struct's directive applies to the type as a whole rather than to a field
+	if sf.Anonymous && (sf.Type.Kind() == reflect.Struct || sf.Type.Kind() == reflect.Ptr && sf.Type.Elem().Kind() == reflect.Struct) {
+		if len(fa.permissions[""]) > 0 || len(fa.permissions) > 1 {
+			typeAccessPermissions[st] = fa.permissions
+		}
+		return nil, nil
+	}
+
+	if fa.hasMaskedRead() {
+		if fa.mask == nil {
+			fa.mask = placeholderMask(defaultMaskPlaceholder)
+		}
+
+		if ft := sf.Type; ft.Kind() != reflect.String && (ft.Kind() != reflect.Ptr || ft.Elem().Kind() != reflect.String) {
+			return nil, gomerr.Configuration("Masked read ('m') is only supported for string fields").AddAttribute("Field", sf.Name)
+		}
+	} else if fa.mask != nil {
+		return nil, gomerr.Configuration("A 'mask' requires at least one principal with masked read ('m')").AddAttribute("Field", sf.Name)
+	}
+
+	return accessApplier{
+		fieldAccess: fa,
+		fieldName:   sf.Name,
+		zeroVal:     reflect.Zero(sf.Type),
+	}, nil
+}
+
+// fieldAccess holds the result of parsing an 'access' directive.
+type fieldAccess struct {
+	permissions map[string]principalPermissions // tenant ("" for default) -> permissions
+	masked      map[string][]bool               // tenant ("" for default) -> whether each principal has masked read
+	provided    bool
+	mask        func(string) string
+}
+
+func (fa fieldAccess) hasMaskedRead() bool {
+	for _, masked := range fa.masked {
+		for _, m := range masked {
+			if m {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func parseDirective(directive string) (fieldAccess, gomerr.Gomerr) {
+	tenantDirectives := strings.Split(directive, tenantSeparator)
+
+	fa := fieldAccess{permissions: make(map[string]principalPermissions), masked: make(map[string][]bool)}
+	if ge := fa.parsePermissions(tenantDirectives[0], ""); ge != nil {
+		return fa, ge
+	}
+
+	for _, tenantDirective := range tenantDirectives[1:] {
+		tenantAndMode := strings.SplitN(tenantDirective, tenantModeSeparator, 2)
+		if len(tenantAndMode) != 2 {
+			return fa, gomerr.Configuration("Tenant 'access' permissions must be of the form <tenant>=<mode>").AddAttribute("Directive", tenantDirective)
+		}
+
+		tenant := strings.TrimSpace(tenantAndMode[0])
+		if tenant == maskKey {
+			mask, ge := maskFor(strings.TrimSpace(tenantAndMode[1]))
+			if ge != nil {
+				return fa, ge
+			}
+			fa.mask = mask
+			continue
+		}
+
+		if _, ok := fieldAccessPrincipalSets[tenant]; !ok {
+			return fa, gomerr.Configuration("No field access principals registered for tenant").AddAttribute("Tenant", tenant)
+		}
+
+		if ge := fa.parsePermissions(tenantAndMode[1], tenant); ge != nil {
+			return fa, ge
+		}
+	}
+
+	return fa, nil
+}
+
+// usedAccessRegexp returns the regexp for parsing a mode string. Once used, no more custom permissions can be
+// registered.
+func usedAccessRegexp() *regexp.Regexp {
+	accessRegexpLock.Lock()
+	defer accessRegexpLock.Unlock()
+
+	accessRegexpUsed = true
+	return accessRegexp
+}
+
+func (fa *fieldAccess) parsePermissions(mode string, tenant string) gomerr.Gomerr {
 	perPrincipalPermissions := make([]map[string]string, 0)
-	for _, match := range accessRegexp.FindAllStringSubmatch(directive, -1) {
+	for _, match := range usedAccessRegexp().FindAllStringSubmatch(mode, -1) {
 		values := make(map[string]string)
 		for i, value := range match {
 			key := accessGroups[i]
@@ -73,13 +188,14 @@ func (ap accessApplierProvider) Applier(_ reflect.Type, sf reflect.StructField,
 
 	// If a field has defined no access permissions (by it being absent or via the empty string), we bypass the error
 	// and the resulting (empty) fieldPermissions will deny access to all registered principals.
-	if ppPermissionsCount > 0 && ppPermissionsCount != len(fieldAccessPrincipalIndexes) {
-		return nil, gomerr.Configuration("Incorrect number of 'access' AccessPermissions").
-			AddAttribute("Expected", len(fieldAccessPrincipalIndexes)).
+	if ppPermissionsCount > 0 && ppPermissionsCount != len(fieldAccessPrincipalSets[tenant]) {
+		return gomerr.Configuration("Incorrect number of 'access' AccessPermissions").
+			AddAttribute("Expected", len(fieldAccessPrincipalSets[tenant])).
 			AddAttribute("Actual", len(perPrincipalPermissions))
 	}
 
-	var fieldPermissions principalPermissions
+	fieldPermissions := make(principalPermissions, ppPermissionsCount)
+	masked := make([]bool, ppPermissionsCount)
 	var provided bool
 	for i := 0; i < ppPermissionsCount; i++ {
 		var principalAccess AccessPermissions
@@ -87,6 +203,8 @@ func (ap accessApplierProvider) Applier(_ reflect.Type, sf reflect.StructField,
 		switch perPrincipalPermissions[i]["read"][0] {
 		case ReadChar:
 			principalAccess |= ReadPermission
+		case MaskedReadChar:
+			masked[i] = true
 		case DenyChar:
 			// nothing to set
 		}
@@ -105,36 +223,126 @@ func (ap accessApplierProvider) Applier(_ reflect.Type, sf reflect.StructField,
 			// nothing to set
 		}
 
-		if i > 0 && provides || provided && writable(principalAccess) {
-			return nil, gomerr.Configuration("To provide Principal permissions (other than the leftmost) cannot specify 'p'." +
+		for _, c := range perPrincipalPermissions[i]["extra"] {
+			if c == DeleteChar {
+				principalAccess |= DeletePermission
+			} else {
+				principalAccess |= customPermissionChars[c]
+			}
+		}
+
+		if i > 0 && provides || provided && principalAccess&WritePermissions != 0 {
+			return gomerr.Configuration("To provide Principal permissions (other than the leftmost) cannot specify 'p'." +
 				" If 'p' was correctly specified, all other principals must indicate '-' for their write permissions.")
 		}
 
-		fieldPermissions = (fieldPermissions << permissionsPerPrincipal) | principalPermissions(principalAccess)
+		fieldPermissions[i] = principalAccess
 	}
 
-	return accessApplier{
-		fieldName:   sf.Name,
-		permissions: fieldPermissions,
-		provided:    provided,
-		zeroVal:     reflect.Zero(sf.Type),
-	}, nil
+	fa.permissions[tenant] = fieldPermissions
+	fa.masked[tenant] = masked
+	fa.provided = fa.provided || provided
+
+	return nil
+}
+
+// typeAccessPermissions holds the permissions specified on an embedded struct, keyed by the embedding struct's type.
+var typeAccessPermissions = make(map[reflect.Type]map[string]principalPermissions)
+
+// TypeAccessGranted returns whether the subject's field access principals (combined) have the specified permissions for the
+// struct type as a whole (see NewAccessTool). A type without an 'access' directive on an embedded struct grants all
+// permissions, as does a request for NoPermissions. The type is first prepared with DefaultAccessTool if necessary.
+func TypeAccessGranted(subject Subject, structType reflect.Type, permissionsNeeded AccessPermissions) (bool, gomerr.Gomerr) {
+	if permissionsNeeded == NoPermissions {
+		return true, nil
+	}
+
+	if ge := structs.Preprocess(structType, DefaultAccessTool); ge != nil {
+		return false, ge
+	}
+
+	for structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+
+	permissions, ok := typeAccessPermissions[structType]
+	if !ok {
+		return true, nil
+	}
+
+	return accessApplier{fieldAccess: fieldAccess{permissions: permissions}}.grants(fieldAccessPrincipals(subject), permissionsNeeded), nil
 }
 
 type accessApplier struct {
-	fieldName   string
-	permissions principalPermissions
-	provided    bool
-	zeroVal     reflect.Value
+	fieldAccess
+	fieldName string
+	zeroVal   reflect.Value
+}
+
+// grants returns whether the principals' combined permissions include all of the ones needed.
+func (a accessApplier) grants(principals []AccessPrincipal, permissionsNeeded AccessPermissions) bool {
+	if permissionsNeeded == NoPermissions {
+		return false // TODO: should this return true or false?
+	}
+
+	var combined AccessPermissions
+	for _, principal := range principals {
+		combined |= a.principalAccessPermissions(principal)
+	}
+
+	return combined&permissionsNeeded == permissionsNeeded
+}
+
+func (a accessApplier) principalAccessPermissions(principal AccessPrincipal) AccessPermissions {
+	switch principal.name {
+	case NoAccess:
+		return NoPermissions
+	case ReadWriteAll:
+		return ^AccessPermissions(NoPermissions)
+	case ReadAll:
+		return ReadPermission
+	}
+
+	tenant, ok := a.tenantFor(principal)
+	if !ok {
+		return NoPermissions
+	}
+
+	return a.permissions[tenant].principalAccessPermissions(principal)
+}
+
+// maskedRead returns whether any of the principals may read a masked version of the field's value.
+func (a accessApplier) maskedRead(principals []AccessPrincipal) bool {
+	for _, principal := range principals {
+		tenant, ok := a.tenantFor(principal)
+		if !ok {
+			continue
+		}
+
+		if index, ok := fieldAccessPrincipalSets[principal.tenant][principal]; ok && index < len(a.masked[tenant]) && a.masked[tenant][index] {
+			return true
+		}
+	}
+	return false
+}
+
+// tenantFor returns the key of the permissions that apply to the principal. A tenant without its own permissions uses
+// the default ones, which only make sense if its set is the same size.
+func (a accessApplier) tenantFor(principal AccessPrincipal) (string, bool) {
+	if _, ok := a.permissions[principal.tenant]; ok {
+		return principal.tenant, true
+	}
+
+	return "", len(a.permissions[""]) == len(fieldAccessPrincipalSets[principal.tenant])
 }
 
-func (a accessApplier) Apply(_ reflect.Value, fv reflect.Value, tc *structs.ToolContext) gomerr.Gomerr {
+func (a accessApplier) Apply(sv reflect.Value, fv reflect.Value, tc *structs.ToolContext) gomerr.Gomerr {
 	accessAction, ok := tc.Get(accessToolAction).(action)
 	if !ok {
 		return nil // no action specified, return
 	}
 
-	return accessAction.do(fv, a, tc)
+	return accessAction.do(sv, fv, a, tc)
 }
 
 const (
@@ -143,29 +351,84 @@ const (
 )
 
 type action interface {
-	do(fieldValue reflect.Value, accessTool accessApplier, toolContext *structs.ToolContext) gomerr.Gomerr
+	do(structValue reflect.Value, fieldValue reflect.Value, accessTool accessApplier, toolContext *structs.ToolContext) gomerr.Gomerr
 }
 
+// AddClearIfDeniedToContext sets up the access tool to clear the value of any field for which the subject's field
+// access principals, combined, don't have the specified permission.
 func AddClearIfDeniedToContext(subject Subject, accessPermission AccessPermissions, tcs ...*structs.ToolContext) *structs.ToolContext {
 	// If no access principal, all permissions will be denied
-	accessPrincipal, _ := subject.Principal(fieldAccessPrincipal).(AccessPrincipal)
-	return structs.EnsureContext(tcs...).Put(accessToolAction, remover{accessPrincipal, accessPermission})
+	return structs.EnsureContext(tcs...).Put(accessToolAction, remover{principals: fieldAccessPrincipals(subject), permission: accessPermission})
+}
+
+// AddRejectIfDeniedToContext is like AddClearIfDeniedToContext, but rather than clearing the value of a field that
+// the subject's field access principals can't write, a gomerr.BadValueError (ReadOnlyValueType) is returned for it
+// if it has a non-zero value. As the tool is applied to every field, the errors for all such fields are returned
+// together. Fields being read are cleared (or masked) as with AddClearIfDeniedToContext.
+//
+// If the struct is an explicit update (see data.ExplicitUpdate), each of its changed fields is treated as provided even
+// if its value is zero (e.g. it's removed), and a field that is cleared is also discarded from the changes.
+func AddRejectIfDeniedToContext(subject Subject, accessPermission AccessPermissions, tcs ...*structs.ToolContext) *structs.ToolContext {
+	return structs.EnsureContext(tcs...).Put(accessToolAction, remover{principals: fieldAccessPrincipals(subject), permission: accessPermission, reject: true})
+}
+
+func fieldAccessPrincipals(subject Subject) []AccessPrincipal {
+	var accessPrincipals []AccessPrincipal
+	for _, principal := range PrincipalsOf(subject, fieldAccessPrincipal) {
+		if accessPrincipal, ok := principal.(AccessPrincipal); ok {
+			accessPrincipals = append(accessPrincipals, accessPrincipal)
+		}
+	}
+
+	return accessPrincipals
 }
 
 type remover struct {
-	principal  AccessPrincipal
+	principals []AccessPrincipal
 	permission AccessPermissions
+	reject     bool // return an error rather than clear a non-writable value
 }
 
-func (r remover) do(fv reflect.Value, aa accessApplier, _ *structs.ToolContext) (ge gomerr.Gomerr) {
+func (r remover) do(sv reflect.Value, fv reflect.Value, aa accessApplier, _ *structs.ToolContext) (ge gomerr.Gomerr) {
 	defer func() {
 		if r := recover(); r != nil {
 			ge = gomerr.Unprocessable("Unable to remove non-writable field", r)
 		}
 	}()
 
-	if !aa.permissions.grants(r.principal, r.permission) && !(aa.provided && writable(r.permission)) {
-		fv.Set(aa.zeroVal)
+	if aa.grants(r.principals, r.permission) || aa.provided && writable(r.permission) {
+		return nil
+	}
+
+	if r.permission == ReadPermission && aa.mask != nil && aa.maskedRead(r.principals) {
+		maskValue(fv, aa.mask)
+		return nil
+	}
+
+	var changes *data.Changes
+	if writable(r.permission) {
+		changes = updateChanges(sv)
+	}
+
+	if r.reject && writable(r.permission) && (!fv.IsZero() || changes != nil && changes.Changed(aa.fieldName)) {
+		return gomerr.ReadOnlyValue(aa.fieldName, fv.Interface()).WithReason("Field is not writable")
+	}
+
+	fv.Set(aa.zeroVal)
+	if changes != nil {
+		changes.Discard(aa.fieldName)
+	}
+	return nil
+}
+
+// updateChanges returns the changes of an explicit update, or nil if the struct isn't one.
+func updateChanges(sv reflect.Value) *data.Changes {
+	if !sv.CanAddr() {
+		return nil
+	}
+
+	if explicitUpdate, ok := sv.Addr().Interface().(data.ExplicitUpdate); ok {
+		return explicitUpdate.UpdateChanges()
 	}
 	return nil
 }
@@ -176,7 +439,7 @@ func AddCopyProvidedToContext(fromStruct reflect.Value, tcs ...*structs.ToolCont
 
 type copyProvided reflect.Value
 
-func (cf copyProvided) do(fv reflect.Value, aa accessApplier, _ *structs.ToolContext) (ge gomerr.Gomerr) {
+func (cf copyProvided) do(_ reflect.Value, fv reflect.Value, aa accessApplier, _ *structs.ToolContext) (ge gomerr.Gomerr) {
 	defer func() {
 		if r := recover(); r != nil {
 			ge = gomerr.Unprocessable("Unable to copy field", r)
@@ -196,6 +459,39 @@ func (cf copyProvided) do(fv reflect.Value, aa accessApplier, _ *structs.ToolCon
 	return nil
 }
 
+// SensitiveFields returns the names of v's fields that have 'access' permissions defined (by DefaultAccessTool's
+// directives) but that at least one field access principal can't read in the clear, such as a secret that may be set
+// but never returned, or a value that some principals only see masked.
+func SensitiveFields(v interface{}) (map[string]bool, gomerr.Gomerr) {
+	sensitive := make(map[string]bool)
+	tc := structs.EnsureContext().Put(accessToolAction, sensitiveCollector(sensitive))
+	if ge := structs.ApplyTools(v, tc, DefaultAccessTool); ge != nil {
+		return nil, ge
+	}
+
+	return sensitive, nil
+}
+
+type sensitiveCollector map[string]bool
+
+func (sc sensitiveCollector) do(_ reflect.Value, _ reflect.Value, aa accessApplier, _ *structs.ToolContext) gomerr.Gomerr {
+	if aa.mask != nil {
+		sc[aa.fieldName] = true
+		return nil
+	}
+
+	for _, permissions := range aa.permissions {
+		for _, p := range permissions {
+			if p&ReadPermission == 0 {
+				sc[aa.fieldName] = true
+				return nil
+			}
+		}
+	}
+
+	return nil
+}
+
 func writable(permissions AccessPermissions) bool {
 	return permissions&LifecyclePermissions != 0
 }
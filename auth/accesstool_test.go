@@ -1,12 +1,16 @@
 package auth_test
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"reflect"
 	"testing"
 
 	"github.com/jt0/gomer/_test/assert"
 	"github.com/jt0/gomer/_test/helpers/structs_test"
 	"github.com/jt0/gomer/auth"
+	"github.com/jt0/gomer/data"
 	"github.com/jt0/gomer/gomerr"
 	"github.com/jt0/gomer/structs"
 )
@@ -31,6 +35,9 @@ var (
 
 	sOne = auth.NewSubject(one)
 	sTwo = auth.NewSubject(two)
+
+	// Custom permissions are registered before any 'access' directive is parsed
+	approve = auth.RegisterCustomPermission('a')
 )
 
 func TestAccessTool(t *testing.T) {
@@ -106,3 +113,210 @@ type testDirectivesProvider struct {
 func (t testDirectivesProvider) Get(reflect.Type, reflect.StructField) string {
 	return t.directive
 }
+
+type ManyPrincipalsTest struct {
+	A string `access:"rwrwrwrcrur-"`
+	B string `access:"r-r-r-r-r---|acme=rwr-"`
+}
+
+func TestManyPrincipalsAndTenants(t *testing.T) {
+	var principals []auth.AccessPrincipal
+	for _, name := range []string{"p1", "p2", "p3", "p4", "p5", "p6"} {
+		principals = append(principals, auth.NewFieldAccessPrincipal(name))
+	}
+	auth.RegisterFieldAccessPrincipals(principals...)
+	defer auth.RegisterFieldAccessPrincipals(one, two)
+
+	acmeAdmin, acmeGuest := auth.NewTenantFieldAccessPrincipal("acme", "admin"), auth.NewTenantFieldAccessPrincipal("acme", "guest")
+	assert.Assert(t, panics(func() { auth.RegisterTenantFieldAccessPrincipals("mask") }), "Expected the 'mask' tenant name to be reserved")
+	auth.RegisterTenantFieldAccessPrincipals("acme", acmeAdmin, acmeGuest)
+
+	tool := auth.NewAccessTool(structs.StructTagDirectiveProvider{TagKey: "access"})
+	structs_test.RunTests(t, []structs_test.TestCase{
+		{Name: "Fifth of six can update", Tool: tool, Context: clear(auth.NewSubject(principals[4]), auth.UpdatePermission), Input: &ManyPrincipalsTest{"A", "B"}, Expected: &ManyPrincipalsTest{A: "A"}},
+		{Name: "Sixth of six can't write", Tool: tool, Context: clear(auth.NewSubject(principals[5]), auth.CreatePermission), Input: &ManyPrincipalsTest{"A", "B"}, Expected: &ManyPrincipalsTest{}},
+		{Name: "Tenant admin can write", Tool: tool, Context: clear(auth.NewSubject(acmeAdmin), auth.CreatePermission), Input: &ManyPrincipalsTest{"A", "B"}, Expected: &ManyPrincipalsTest{B: "B"}},
+		{Name: "Tenant guest can't write", Tool: tool, Context: clear(auth.NewSubject(acmeGuest), auth.CreatePermission), Input: &ManyPrincipalsTest{"A", "B"}, Expected: &ManyPrincipalsTest{}},
+	})
+}
+
+type Embedded struct{}
+
+type TypeAccessTest struct {
+	Embedded `access:"rwdar-"`
+
+	A string `access:"rwr-"`
+}
+
+func TestTypeAccessWithDeleteAndCustomPermissions(t *testing.T) {
+	auth.RegisterFieldAccessPrincipals(one, two)
+	assert.Equals(t, approve, auth.RegisterCustomPermission('a'))
+
+	testType := reflect.TypeOf(&TypeAccessTest{})
+	for _, tt := range []struct {
+		name        string
+		subject     auth.Subject
+		permissions auth.AccessPermissions
+		expected    bool
+	}{
+		{"'one' can delete", sOne, auth.DeletePermission, true},
+		{"'one' can approve", sOne, approve, true},
+		{"'two' can read", sTwo, auth.ReadPermission, true},
+		{"'two' can't delete", sTwo, auth.DeletePermission, false},
+		{"'two' can't approve", sTwo, approve, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			granted, ge := auth.TypeAccessGranted(tt.subject, testType, tt.permissions)
+			assert.Success(t, ge)
+			assert.Equals(t, tt.expected, granted)
+		})
+	}
+
+	granted, ge := auth.TypeAccessGranted(sTwo, reflect.TypeOf(&AccessTest{}), auth.DeletePermission)
+	assert.Success(t, ge)
+	assert.Assert(t, granted, "Expected a type without type-level permissions to grant all")
+}
+
+func TestCustomPermissionRegisteredAfterParsingPanics(t *testing.T) {
+	auth.RegisterFieldAccessPrincipals(one, two)
+	_, ge := auth.TypeAccessGranted(sOne, reflect.TypeOf(&TypeAccessTest{}), approve)
+	assert.Success(t, ge)
+
+	defer func() {
+		assert.Assert(t, recover() != nil, "Expected registering a custom permission after parsing to panic")
+	}()
+	auth.RegisterCustomPermission('z')
+}
+
+type CombinedTest struct {
+	A string `access:"rcru"`
+	B string `access:"r-ru"`
+}
+
+func TestMultiplePrincipalsCombinePermissions(t *testing.T) {
+	auth.RegisterFieldAccessPrincipals(one, two)
+
+	both := auth.NewSubject()
+	both.AddPrincipals(auth.FieldAccessPrincipalsFromClaim("one two", "")...)
+	assert.Equals(t, 2, len(auth.PrincipalsOf(both, one.Type())))
+
+	// Neither principal alone can both create and update 'A', but combined they can
+	structs_test.RunTests(t, []structs_test.TestCase{
+		{Name: "'one' alone", Tool: auth.DefaultAccessTool, Context: clear(sOne, auth.WritePermissions), Input: &CombinedTest{A: "A", B: "B"}, Expected: &CombinedTest{}},
+		{Name: "'one' and 'two'", Tool: auth.DefaultAccessTool, Context: clear(both, auth.WritePermissions), Input: &CombinedTest{A: "A", B: "B"}, Expected: &CombinedTest{A: "A"}},
+	})
+}
+
+var hashMaskKey = []byte("0123456789abcdef0123456789abcdef")
+
+type MaskedTest struct {
+	Ssn  string  `access:"rwm-|mask=last4"`
+	Card *string `access:"rwm-|mask=hash"`
+	Note string  `access:"rwm-|mask=placeholder(hidden)"`
+	Pin  string  `access:"rwm-"`
+	Tax  string  `access:"rwm-"` // empty values stay empty
+}
+
+func TestMaskedRead(t *testing.T) {
+	auth.RegisterFieldAccessPrincipals(one, two)
+
+	type NoKeyMask struct {
+		Card string `access:"rwm-|mask=hash"`
+	}
+	auth.SetHashMaskKey(nil)
+	ge := structs.ApplyTools(&NoKeyMask{}, clear(sTwo, auth.ReadPermission), auth.DefaultAccessTool)
+	assert.ErrorType(t, ge, &gomerr.ConfigurationError{}, "Expected the 'hash' mask to require a key")
+
+	auth.SetHashMaskKey(hashMaskKey)
+	card := "4111111111111111"
+	mac := hmac.New(sha256.New, hashMaskKey)
+	mac.Write([]byte(card))
+	maskedCard := hex.EncodeToString(mac.Sum(nil))[:12]
+	input := func() *MaskedTest {
+		c := card
+		return &MaskedTest{Ssn: "123-45-6789", Card: &c, Note: "note", Pin: "1234"}
+	}
+
+	structs_test.RunTests(t, []structs_test.TestCase{
+		{Name: "Unmasked as 'one'", Tool: auth.DefaultAccessTool, Context: clear(sOne, auth.ReadPermission), Input: input(), Expected: input()},
+		{Name: "Masked as 'two'", Tool: auth.DefaultAccessTool, Context: clear(sTwo, auth.ReadPermission), Input: input(), Expected: &MaskedTest{Ssn: "*******6789", Card: &maskedCard, Note: "hidden", Pin: "****"}},
+		{Name: "Empty *string left as is", Tool: auth.DefaultAccessTool, Context: clear(sTwo, auth.ReadPermission), Input: &MaskedTest{Card: new(string)}, Expected: &MaskedTest{Card: new(string)}},
+		{Name: "Cleared on write as 'two'", Tool: auth.DefaultAccessTool, Context: clear(sTwo, auth.CreatePermission), Input: input(), Expected: &MaskedTest{}},
+	})
+
+	type BadMask struct {
+		N int `access:"rwm-"`
+	}
+	ge = structs.ApplyTools(&BadMask{}, clear(sTwo, auth.ReadPermission), auth.DefaultAccessTool)
+	assert.ErrorType(t, ge, &gomerr.ConfigurationError{}, "Expected masking a non-string field to fail")
+}
+
+func TestSensitiveFields(t *testing.T) {
+	auth.RegisterFieldAccessPrincipals(one, two)
+	auth.SetHashMaskKey(hashMaskKey)
+
+	sensitive, ge := auth.SensitiveFields(all())
+	assert.Success(t, ge)
+	assert.Equals(t, map[string]bool{"E": true, "F": true, "G": true, "H": true, "J": true}, sensitive)
+
+	sensitive, ge = auth.SensitiveFields(&MaskedTest{})
+	assert.Success(t, ge)
+	assert.Equals(t, map[string]bool{"Ssn": true, "Card": true, "Note": true, "Pin": true, "Tax": true}, sensitive)
+}
+
+func TestRejectIfDenied(t *testing.T) {
+	auth.RegisterFieldAccessPrincipals(one, two)
+
+	reject := func(subject auth.Subject) *structs.ToolContext {
+		return auth.AddRejectIfDeniedToContext(subject, auth.CreatePermission)
+	}
+
+	v := allExpected() // K has no permissions, so it can't be written by anyone
+	assert.Success(t, structs.ApplyTools(v, reject(sOne), auth.DefaultAccessTool))
+	assert.Equals(t, allExpected(), v)
+
+	ge := structs.ApplyTools(&AccessTest{A: "A", C: "C"}, reject(sTwo), auth.DefaultAccessTool)
+	assert.ErrorType(t, ge, &gomerr.BadValueError{}, "Expected writing a non-creatable field to fail")
+	assert.Equals(t, gomerr.ReadOnlyValueType, ge.(*gomerr.BadValueError).Type)
+
+	ge = structs.ApplyTools(all(), reject(sTwo), auth.DefaultAccessTool)
+	assert.ErrorType(t, ge, &gomerr.BatchError{}, "Expected an error for each non-creatable field")
+	assert.Equals(t, 5, len(ge.(*gomerr.BatchError).Errors())) // C, D, G, H, K
+}
+
+type ExplicitAccessTest struct {
+	A string `access:"rwru"`
+	D string `access:"rwr-"`
+
+	changes *data.Changes
+}
+
+func (e *ExplicitAccessTest) UpdateChanges() *data.Changes {
+	return e.changes
+}
+
+func TestExplicitUpdate(t *testing.T) {
+	auth.RegisterFieldAccessPrincipals(one, two)
+
+	changes := func() *data.Changes {
+		return &data.Changes{Set: map[string]bool{"A": true}, Removed: map[string]bool{"D": true}}
+	}
+
+	// Removing D sets it to its zero value, but it's still rejected as it's not updatable by 'two'
+	ge := structs.ApplyTools(&ExplicitAccessTest{changes: changes()}, auth.AddRejectIfDeniedToContext(sTwo, auth.UpdatePermission), auth.DefaultAccessTool)
+	assert.ErrorType(t, ge, &gomerr.BadValueError{}, "Expected removing a non-updatable field to fail")
+	assert.Equals(t, "D", ge.(*gomerr.BadValueError).Name)
+
+	v := &ExplicitAccessTest{changes: changes()}
+	assert.Success(t, structs.ApplyTools(v, clear(sTwo, auth.UpdatePermission), auth.DefaultAccessTool))
+	assert.Equals(t, &data.Changes{Set: map[string]bool{"A": true}, Removed: map[string]bool{}}, v.changes)
+}
+
+func panics(fn func()) (panicked bool) {
+	defer func() {
+		panicked = recover() != nil
+	}()
+
+	fn()
+	return false
+}
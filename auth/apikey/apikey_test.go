@@ -0,0 +1,124 @@
+package apikey_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jt0/gomer/_test/assert"
+	"github.com/jt0/gomer/_test/helpers/stores"
+	"github.com/jt0/gomer/auth"
+	"github.com/jt0/gomer/auth/apikey"
+	"github.com/jt0/gomer/gomerr"
+	"github.com/jt0/gomer/limit"
+)
+
+func TestApiKeyRotation(t *testing.T) {
+	now := time.Now()
+	clock := func() time.Time { return now }
+	store := stores.NewMemoryStore()
+
+	key := &apikey.Key{KeyId: "k1", User: "u1"}
+	key.Rotate("old", 0, now)
+	key.Rotate("new", time.Hour, now)
+	assert.Success(t, store.Create(key))
+
+	authenticator := apikey.NewAuthenticator(store, apikey.AuthenticatorClock(clock))
+
+	subject, ge := authenticator.Subject("k1.new")
+	assert.Success(t, ge)
+	assert.Equals(t, "u1", subject.Principal(auth.User).Id())
+	assert.Equals(t, auth.NoFieldAccess, subject.Principal(auth.NoFieldAccess.Type()))
+
+	_, ge = authenticator.Subject("k1.old")
+	assert.Success(t, ge)
+
+	now = now.Add(2 * time.Hour)
+	_, ge = authenticator.Subject("k1.old")
+	assert.ErrorType(t, ge, &gomerr.BadValueError{}, "Expected old secret to stop working after the overlap")
+
+	_, ge = authenticator.Subject("k2.new")
+	assert.Equals(t, gomerr.InvalidValueType, ge.(*gomerr.BadValueError).Type)
+
+	_, ge = authenticator.Subject("nope")
+	assert.Equals(t, gomerr.MalformedValueType, ge.(*gomerr.BadValueError).Type)
+}
+
+func TestSignedRequest(t *testing.T) {
+	now := time.Now()
+	clock := func() time.Time { return now }
+	store := stores.NewMemoryStore()
+	secret := []byte("signing-secret")
+	assert.Success(t, store.Create(&apikey.Key{KeyId: "k1", Account: "a1", SigningSecret: secret}))
+
+	verifier := apikey.NewSignatureVerifier(store, apikey.VerifierClock(clock))
+
+	request := httptest.NewRequest("POST", "https://example.com/orders?b=2&a=1", strings.NewReader(`{"x":1}`))
+	assert.Success(t, apikey.SignRequest(request, "k1", secret, now))
+
+	subject, ge := verifier.Subject(request)
+	assert.Success(t, ge)
+	assert.Equals(t, "a1", subject.Principal(auth.Account).Id())
+
+	_, ge = verifier.Subject(request)
+	assert.ErrorType(t, ge, &gomerr.BadValueError{}, "Expected replayed request to fail")
+
+	replayed := httptest.NewRequest("POST", "https://example.com/orders?b=2&a=1", strings.NewReader(`{"x":1}`))
+	replayed.Header = request.Header.Clone()
+	authorization := replayed.Header.Get("Authorization")
+	signatureIndex := strings.Index(authorization, "Signature=") + len("Signature=")
+	replayed.Header.Set("Authorization", authorization[:signatureIndex]+strings.ToUpper(authorization[signatureIndex:]))
+	_, ge = verifier.Subject(replayed)
+	assert.ErrorType(t, ge, &gomerr.BadValueError{}, "Expected replayed request with a differently cased signature to fail")
+	assert.Equals(t, "Request has already been received", ge.Attributes()["Reason"])
+
+	tampered := httptest.NewRequest("POST", "https://example.com/orders?b=2&a=1", strings.NewReader(`{"x":2}`))
+	tampered.Header = request.Header.Clone()
+	_, ge = verifier.Subject(tampered)
+	assert.Equals(t, gomerr.InvalidValueType, ge.(*gomerr.BadValueError).Type)
+
+	stale := httptest.NewRequest("GET", "https://example.com/orders", nil)
+	assert.Success(t, apikey.SignRequest(stale, "k1", secret, now.Add(-time.Hour)))
+	_, ge = verifier.Subject(stale)
+	assert.Equals(t, gomerr.InvalidValueType, ge.(*gomerr.BadValueError).Type)
+}
+
+func TestSignedRequestBodyLimits(t *testing.T) {
+	now := time.Now()
+	store := stores.NewMemoryStore()
+	secret := []byte("signing-secret")
+	assert.Success(t, store.Create(&apikey.Key{KeyId: "k1", Account: "a1", SigningSecret: secret}))
+
+	verifier := apikey.NewSignatureVerifier(store, apikey.VerifierClock(func() time.Time { return now }), apikey.MaxBodySize(4))
+	signed := func(body string, contentLength int64) gomerr.Gomerr {
+		request := httptest.NewRequest("POST", "https://example.com/orders", strings.NewReader(body))
+		assert.Success(t, apikey.SignRequest(request, "k1", secret, now))
+		request.ContentLength = contentLength
+		_, ge := verifier.Subject(request)
+		return ge
+	}
+
+	assert.Success(t, signed(`{}`, 2))
+	assert.ErrorType(t, signed(`{"x":1}`, 7), &limit.ExceededError{}, "Expected an oversized body to be rejected")
+	assert.ErrorType(t, signed(`{"x":2}`, 2), &limit.ExceededError{}, "Expected a body longer than its content length to be rejected")
+
+	ge := signed(`{}`, -1)
+	assert.ErrorType(t, ge, &gomerr.BadValueError{}, "Expected a streamed body to be rejected")
+	assert.Equals(t, gomerr.InvalidValueType, ge.(*gomerr.BadValueError).Type)
+}
+
+func TestMemoryReplayCache(t *testing.T) {
+	now := time.Now()
+	cache := apikey.NewMemoryReplayCache(func() time.Time { return now })
+
+	assert.Assert(t, !cache.Seen("s1", now.Add(time.Minute)))
+	assert.Assert(t, !cache.Seen("s2", now.Add(3*time.Minute)))
+	assert.Assert(t, !cache.Seen("s3", now.Add(2*time.Minute)))
+	assert.Assert(t, cache.Seen("s1", now.Add(time.Minute)))
+
+	now = now.Add(2 * time.Minute)
+	assert.Assert(t, !cache.Seen("s1", now.Add(time.Minute)), "Expected s1 to have expired")
+	assert.Assert(t, !cache.Seen("s3", now.Add(time.Minute)), "Expected s3 to have expired")
+	assert.Assert(t, cache.Seen("s2", now.Add(time.Minute)), "Expected s2 to still be recorded")
+}
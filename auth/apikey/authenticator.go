@@ -0,0 +1,84 @@
+package apikey
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jt0/gomer/auth"
+	"github.com/jt0/gomer/data"
+	"github.com/jt0/gomer/data/dataerr"
+	"github.com/jt0/gomer/gomerr"
+)
+
+const keyName = "ApiKey"
+
+// Authenticator verifies API keys presented as "<keyId>.<secret>" against Keys read from a data.Store.
+type Authenticator struct {
+	store data.Store
+	now   func() time.Time
+}
+
+func NewAuthenticator(store data.Store, options ...func(*Authenticator)) *Authenticator {
+	a := &Authenticator{store: store, now: time.Now}
+	for _, option := range options {
+		option(a)
+	}
+
+	return a
+}
+
+// AuthenticatorClock overrides the function used to get the current time.
+func AuthenticatorClock(now func() time.Time) func(*Authenticator) {
+	return func(a *Authenticator) {
+		a.now = now
+	}
+}
+
+// Subject returns the Subject for the key if the presented value is valid. Possible errors:
+//
+//	gomerr.BadValueError (MalformedValueType):
+//	    if the value isn't of the form "<keyId>.<secret>"
+//	gomerr.BadValueError (InvalidValueType):
+//	    if the key doesn't exist, the secret doesn't match, or the key is disabled
+//	gomerr.BadValueError (ExpiredValueType):
+//	    if the key has expired
+func (a *Authenticator) Subject(presented string) (auth.Subject, gomerr.Gomerr) {
+	keyId, secret, found := strings.Cut(presented, ".")
+	if !found || keyId == "" || secret == "" {
+		return nil, gomerr.MalformedValue(keyName, nil).WithReason("Expected <keyId>.<secret>")
+	}
+
+	key, ge := readKey(a.store, keyId)
+	if ge != nil {
+		return nil, ge
+	}
+
+	now := a.now()
+	if !key.matches(secret, now) {
+		return nil, invalidKey(keyId)
+	}
+
+	if ge = key.usable(now); ge != nil {
+		return nil, ge
+	}
+
+	return key.Subject(), nil
+}
+
+func readKey(store data.Store, keyId string) (*Key, gomerr.Gomerr) {
+	key := &Key{KeyId: keyId}
+	if ge := store.Read(key); ge != nil {
+		if errors.Is(ge, &dataerr.PersistableNotFoundError{}) {
+			return nil, invalidKey(keyId)
+		}
+		return nil, ge
+	}
+
+	return key, nil
+}
+
+// invalidKey doesn't distinguish between an unknown key and an incorrect secret
+func invalidKey(keyId string) gomerr.Gomerr {
+	return gomerr.InvalidValue(keyName, keyId, "valid key").WithReason("Unknown key or incorrect secret")
+}
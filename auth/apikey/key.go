@@ -0,0 +1,127 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"time"
+
+	"github.com/jt0/gomer/auth"
+	"github.com/jt0/gomer/data"
+	"github.com/jt0/gomer/gomerr"
+)
+
+// Key is a persisted API key. Only hashes of its secrets are stored (see HashSecret), and a key can have more than one
+// valid secret at a time so that a new one can be issued before the old one stops working (see Rotate).
+//
+// A Key used to verify signed requests (see SignatureVerifier) also needs its SigningSecret. Unlike the hashed API key
+// secrets, the signing secret must be recoverable, so applications should encrypt it at rest (e.g. with gomer/crypto).
+type Key struct {
+	KeyId         string
+	Secrets       []Secret
+	SigningSecret []byte
+	Account       string
+	User          string
+	Groups        []string
+	Roles         []string // names of registered field access principals
+	Tenant        string   // selects the tenant's field access principal set
+	Disabled      bool
+	ExpiresAt     time.Time // zero if the key doesn't expire
+}
+
+type Secret struct {
+	Hash      string
+	ExpiresAt time.Time // zero if the secret doesn't expire
+}
+
+func (k *Key) TypeName() string {
+	return "ApiKey"
+}
+
+func (k *Key) NewQueryable() data.Queryable {
+	return nil
+}
+
+func (k *Key) Id() string {
+	return k.KeyId
+}
+
+// GenerateSecret returns a new random secret suitable for use as an API key secret or signing secret.
+func GenerateSecret() (string, gomerr.Gomerr) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", gomerr.Internal("Unable to generate secret").Wrap(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func HashSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+// Rotate adds the (hash of the) new secret to the key. Existing secrets remain valid for the overlap period so that
+// callers have time to switch over, after which they're removed the next time the key is rotated.
+func (k *Key) Rotate(newSecret string, overlap time.Duration, now time.Time) {
+	secrets := []Secret{{Hash: HashSecret(newSecret)}}
+	for _, s := range k.Secrets {
+		if !s.ExpiresAt.IsZero() && !now.Before(s.ExpiresAt) {
+			continue // drop expired secrets
+		}
+
+		if expiresAt := now.Add(overlap); s.ExpiresAt.IsZero() || expiresAt.Before(s.ExpiresAt) {
+			s.ExpiresAt = expiresAt
+		}
+		secrets = append(secrets, s)
+	}
+
+	k.Secrets = secrets
+}
+
+func (k *Key) matches(secret string, now time.Time) bool {
+	hash := HashSecret(secret)
+
+	var matched bool
+	for _, s := range k.Secrets {
+		if (s.ExpiresAt.IsZero() || now.Before(s.ExpiresAt)) && subtle.ConstantTimeCompare([]byte(hash), []byte(s.Hash)) == 1 {
+			matched = true
+		}
+	}
+
+	return matched
+}
+
+func (k *Key) usable(now time.Time) gomerr.Gomerr {
+	if k.Disabled {
+		return gomerr.InvalidValue(keyName, k.KeyId, "enabled key").WithReason("Key is disabled")
+	} else if !k.ExpiresAt.IsZero() && !now.Before(k.ExpiresAt) {
+		return gomerr.ValueExpired(keyName, k.ExpiresAt)
+	}
+
+	return nil
+}
+
+// Subject returns a Subject with principals for the key's account, user, groups, and field access roles. If the key
+// has no (recognized) roles, the subject is given auth.NoFieldAccess.
+func (k *Key) Subject() auth.Subject {
+	subject := auth.NewSubject()
+	if k.Account != "" {
+		subject.AddPrincipals(auth.NewPrincipal(auth.Account, k.Account))
+	}
+	if k.User != "" {
+		subject.AddPrincipals(auth.NewPrincipal(auth.User, k.User))
+	}
+	for _, group := range k.Groups {
+		subject.AddPrincipals(auth.NewPrincipal(auth.Group, group))
+	}
+
+	fieldAccessPrincipals := auth.FieldAccessPrincipalsFromClaim(k.Roles, k.Tenant)
+	if len(fieldAccessPrincipals) == 0 {
+		fieldAccessPrincipals = append(fieldAccessPrincipals, auth.NoFieldAccess)
+	}
+	subject.AddPrincipals(fieldAccessPrincipals...)
+
+	return subject
+}
@@ -0,0 +1,320 @@
+package apikey
+
+import (
+	"bytes"
+	"container/heap"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jt0/gomer/auth"
+	"github.com/jt0/gomer/data"
+	"github.com/jt0/gomer/gomerr"
+	"github.com/jt0/gomer/limit"
+)
+
+const (
+	SignatureScheme = "HMAC-SHA256"
+	DateHeader      = "X-Date" // RFC 3339 timestamp of when the request was signed
+
+	signatureName = "Signature"
+)
+
+// SignatureVerifier authenticates requests signed with a Key's SigningSecret. A signed request has an X-Date header
+// and an Authorization header of the form:
+//
+//	HMAC-SHA256 KeyId=<keyId>, SignedHeaders=<lowercase;header;names>, Signature=<hex signature>
+//
+// where the signature is the hex-encoded HMAC-SHA256 of the canonical request (see CanonicalRequest). The signed
+// headers must include host and x-date. Requests whose date is outside the allowed skew are rejected, as are
+// signatures that have already been seen within the skew window. Since the body is read in full to verify its hash,
+// requests with a streamed (i.e. unknown length) body or one larger than the maximum body size are rejected too.
+type SignatureVerifier struct {
+	store       data.Store
+	maxSkew     time.Duration
+	maxBodySize int64
+	now         func() time.Time
+	replayCache ReplayCache
+}
+
+// ReplayCache records signatures so that a signed request can't be replayed. Seen returns true if the signature
+// had already been recorded and hasn't yet expired.
+type ReplayCache interface {
+	Seen(signature string, expiresAt time.Time) bool
+}
+
+func NewSignatureVerifier(store data.Store, options ...func(*SignatureVerifier)) *SignatureVerifier {
+	v := &SignatureVerifier{store: store, maxSkew: 5 * time.Minute, maxBodySize: 1 << 20, now: time.Now}
+	for _, option := range options {
+		option(v)
+	}
+
+	if v.replayCache == nil {
+		v.replayCache = NewMemoryReplayCache(v.now)
+	}
+
+	return v
+}
+
+// MaxSkew sets how far a request's date may differ from the current time. The default is five minutes.
+func MaxSkew(maxSkew time.Duration) func(*SignatureVerifier) {
+	return func(v *SignatureVerifier) {
+		v.maxSkew = maxSkew
+	}
+}
+
+// MaxBodySize sets the largest body, in bytes, that a signed request may have. The default is 1 MiB.
+func MaxBodySize(maxBodySize int64) func(*SignatureVerifier) {
+	return func(v *SignatureVerifier) {
+		v.maxBodySize = maxBodySize
+	}
+}
+
+// VerifierClock overrides the function used to get the current time.
+func VerifierClock(now func() time.Time) func(*SignatureVerifier) {
+	return func(v *SignatureVerifier) {
+		v.now = now
+	}
+}
+
+// WithReplayCache replaces the default in-memory cache, e.g. with one shared across servers.
+func WithReplayCache(replayCache ReplayCache) func(*SignatureVerifier) {
+	return func(v *SignatureVerifier) {
+		v.replayCache = replayCache
+	}
+}
+
+// Subject verifies the request's signature and returns the signing Key's Subject. The request's body is read to
+// compute its hash and then replaced so that it can be read again.
+func (v *SignatureVerifier) Subject(request *http.Request) (auth.Subject, gomerr.Gomerr) {
+	keyId, signedHeaders, signature, ge := parseAuthorization(request.Header.Get("Authorization"))
+	if ge != nil {
+		return nil, ge
+	}
+
+	date, err := time.Parse(time.RFC3339, request.Header.Get(DateHeader))
+	if err != nil {
+		return nil, gomerr.MalformedValue(DateHeader, request.Header.Get(DateHeader)).WithReason("Expected an RFC 3339 timestamp")
+	}
+
+	now := v.now()
+	if date.Before(now.Add(-v.maxSkew)) || date.After(now.Add(v.maxSkew)) {
+		return nil, gomerr.InvalidValue(DateHeader, date, "within "+v.maxSkew.String()+" of the current time")
+	}
+
+	key, ge := readKey(v.store, keyId)
+	if ge != nil {
+		return nil, ge
+	}
+
+	if ge = v.limitBody(request); ge != nil {
+		return nil, ge
+	}
+
+	canonicalRequest, ge := CanonicalRequest(request, signedHeaders)
+	if ge != nil {
+		return nil, ge
+	}
+
+	expected, err := hex.DecodeString(signature)
+	if err != nil || len(key.SigningSecret) == 0 || !hmac.Equal(expected, sign(key.SigningSecret, canonicalRequest)) {
+		return nil, gomerr.InvalidValue(signatureName, nil, "valid signature").WithReason("Signature verification failed")
+	}
+
+	if ge = key.usable(now); ge != nil {
+		return nil, ge
+	}
+
+	// The signature's hex can be in either case, so its canonical form is the one that's recorded
+	if v.replayCache.Seen(hex.EncodeToString(expected), date.Add(v.maxSkew)) {
+		return nil, gomerr.InvalidValue(signatureName, nil, "unused signature").WithReason("Request has already been received")
+	}
+
+	return key.Subject(), nil
+}
+
+// limitBody rejects a request whose body is streamed or larger than the maximum size, and otherwise limits reads of
+// the body to that size in case its content length is wrong.
+func (v *SignatureVerifier) limitBody(request *http.Request) gomerr.Gomerr {
+	if request.Body == nil || request.Body == http.NoBody {
+		return nil
+	}
+
+	if request.ContentLength < 0 {
+		return gomerr.InvalidValue("Content-Length", nil, "known body length").WithReason("A signed request's body can't be streamed")
+	}
+	if request.ContentLength > v.maxBodySize {
+		return limit.Exceeded("SignatureVerifier", "body", limit.DataSize(v.maxBodySize), limit.Unknown, limit.DataSize(request.ContentLength))
+	}
+	request.Body = http.MaxBytesReader(nil, request.Body, v.maxBodySize)
+
+	return nil
+}
+
+// SignRequest sets the X-Date and Authorization headers on the request using the key's id and signing secret. It
+// signs the host and x-date headers along with any additional ones specified.
+func SignRequest(request *http.Request, keyId string, signingSecret []byte, date time.Time, additionalHeaders ...string) gomerr.Gomerr {
+	request.Header.Set(DateHeader, date.UTC().Format(time.RFC3339))
+
+	signedHeaders := []string{"host", "x-date"}
+	for _, h := range additionalHeaders {
+		signedHeaders = append(signedHeaders, strings.ToLower(h))
+	}
+	sort.Strings(signedHeaders)
+
+	canonicalRequest, ge := CanonicalRequest(request, signedHeaders)
+	if ge != nil {
+		return ge
+	}
+
+	request.Header.Set("Authorization", SignatureScheme+" KeyId="+keyId+", SignedHeaders="+strings.Join(signedHeaders, ";")+
+		", Signature="+hex.EncodeToString(sign(signingSecret, canonicalRequest)))
+
+	return nil
+}
+
+// CanonicalRequest returns the string that is signed for the request:
+//
+//	<METHOD>\n<path>\n<sorted, encoded query>\n<header>:<trimmed value>\n...\n<signed;headers>\n<hex sha256 of body>
+func CanonicalRequest(request *http.Request, signedHeaders []string) (string, gomerr.Gomerr) {
+	var body []byte
+	if request.Body != nil {
+		var err error
+		if body, err = io.ReadAll(request.Body); err != nil {
+			var maxBytesError *http.MaxBytesError
+			if errors.As(err, &maxBytesError) {
+				return "", limit.Exceeded("CanonicalRequest", "body", limit.DataSize(maxBytesError.Limit), limit.Unknown, limit.Unknown).Wrap(err)
+			}
+			return "", gomerr.Internal("Failed to read request body content").Wrap(err)
+		}
+		request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	bodyHash := sha256.Sum256(body)
+
+	var sb strings.Builder
+	sb.WriteString(request.Method + "\n")
+	sb.WriteString(request.URL.EscapedPath() + "\n")
+	sb.WriteString(request.URL.Query().Encode() + "\n") // Encode() sorts by key
+	for _, h := range signedHeaders {
+		value := request.Header.Get(h)
+		if h == "host" {
+			if value = request.Host; value == "" {
+				value = request.URL.Host
+			}
+		}
+		sb.WriteString(h + ":" + strings.TrimSpace(value) + "\n")
+	}
+	sb.WriteString(strings.Join(signedHeaders, ";") + "\n")
+	sb.WriteString(hex.EncodeToString(bodyHash[:]))
+
+	return sb.String(), nil
+}
+
+func sign(secret []byte, canonicalRequest string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(canonicalRequest))
+	return mac.Sum(nil)
+}
+
+func parseAuthorization(header string) (keyId string, signedHeaders []string, signature string, ge gomerr.Gomerr) {
+	scheme, params, found := strings.Cut(header, " ")
+	if !found || scheme != SignatureScheme {
+		return "", nil, "", gomerr.MalformedValue("Authorization", nil).WithReason("Expected " + SignatureScheme + " credentials")
+	}
+
+	for _, param := range strings.Split(params, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		switch name {
+		case "KeyId":
+			keyId = value
+		case "SignedHeaders":
+			signedHeaders = strings.Split(value, ";")
+		case "Signature":
+			signature = value
+		}
+	}
+
+	if keyId == "" || signature == "" || !contains(signedHeaders, "host") || !contains(signedHeaders, "x-date") {
+		return "", nil, "", gomerr.MalformedValue("Authorization", nil).WithReason("Missing KeyId, Signature, or required SignedHeaders")
+	}
+
+	return keyId, signedHeaders, signature, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// memoryReplayCache keeps the seen signatures in a map along with a heap ordered by when they expire, so that
+// expired ones can be removed without examining the rest.
+type memoryReplayCache struct {
+	mu       sync.Mutex
+	seen     map[string]struct{}
+	expiries expiryHeap
+	now      func() time.Time
+}
+
+// NewMemoryReplayCache returns a ReplayCache for a single process.
+func NewMemoryReplayCache(now func() time.Time) ReplayCache {
+	return &memoryReplayCache{seen: make(map[string]struct{}), now: now}
+}
+
+func (c *memoryReplayCache) Seen(signature string, expiresAt time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for len(c.expiries) > 0 && !now.Before(c.expiries[0].expiresAt) {
+		delete(c.seen, heap.Pop(&c.expiries).(expiry).signature)
+	}
+
+	if _, seen := c.seen[signature]; seen {
+		return true
+	}
+
+	c.seen[signature] = struct{}{}
+	heap.Push(&c.expiries, expiry{signature: signature, expiresAt: expiresAt})
+	return false
+}
+
+type expiry struct {
+	signature string
+	expiresAt time.Time
+}
+
+type expiryHeap []expiry
+
+func (h expiryHeap) Len() int {
+	return len(h)
+}
+
+func (h expiryHeap) Less(i, j int) bool {
+	return h[i].expiresAt.Before(h[j].expiresAt)
+}
+
+func (h expiryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+}
+
+func (h *expiryHeap) Push(x interface{}) {
+	*h = append(*h, x.(expiry))
+}
+
+func (h *expiryHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,101 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+
+	"github.com/jt0/gomer/gomerr"
+)
+
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+func readJwksFile(path string) (map[string]interface{}, gomerr.Gomerr) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, gomerr.Configuration("Unable to read JWKS file").AddAttribute("Path", path).Wrap(err)
+	}
+
+	var set jwks
+	if err = json.Unmarshal(b, &set); err != nil {
+		return nil, gomerr.Unmarshal("JWKS", b, &set).Wrap(err)
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, k := range set.Keys {
+		key, ge := k.key()
+		if ge != nil {
+			return nil, ge.AddAttribute("Kid", k.Kid)
+		}
+		keys[k.Kid] = key
+	}
+
+	return keys, nil
+}
+
+func (k jwk) key() (interface{}, gomerr.Gomerr) {
+	switch k.Kty {
+	case "oct":
+		return decodeBytes(k.K)
+	case "RSA":
+		n, ge := decodeInt(k.N)
+		if ge != nil {
+			return nil, ge
+		}
+		e, ge := decodeInt(k.E)
+		if ge != nil {
+			return nil, ge
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, gomerr.Configuration("Unsupported JWK curve: " + k.Crv)
+		}
+		x, ge := decodeInt(k.X)
+		if ge != nil {
+			return nil, ge
+		}
+		y, ge := decodeInt(k.Y)
+		if ge != nil {
+			return nil, ge
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	default:
+		return nil, gomerr.Configuration("Unsupported JWK key type: " + k.Kty)
+	}
+}
+
+func decodeBytes(s string) ([]byte, gomerr.Gomerr) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, gomerr.Configuration("Invalid JWK value encoding").Wrap(err)
+	}
+
+	return b, nil
+}
+
+func decodeInt(s string) (*big.Int, gomerr.Gomerr) {
+	b, ge := decodeBytes(s)
+	if ge != nil {
+		return nil, ge
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,330 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/jt0/gomer/auth"
+	"github.com/jt0/gomer/gomerr"
+)
+
+// Supported signing algorithms. A key can only be used with the algorithm that matches its type, so (for example) an
+// RSA public key can never be used as an HMAC secret.
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	ES256 = "ES256"
+)
+
+const tokenName = "Authorization"
+
+// Verifier validates JSON Web Tokens and maps their claims to an auth.Subject's principals.
+type Verifier struct {
+	keys             map[string]interface{} // kid ("" if none) -> []byte, *rsa.PublicKey, or *ecdsa.PublicKey
+	issuer           string
+	audience         string
+	leeway           time.Duration
+	now              func() time.Time
+	principalClaims  map[string]auth.PrincipalType
+	fieldAccessClaim string
+	tenantClaim      string
+	configErrors     []gomerr.Gomerr
+}
+
+type Claims map[string]interface{}
+
+// NewVerifier returns a Verifier configured with the provided options. At least one key must be provided. Unless
+// otherwise configured, the "sub" claim is mapped to an auth.User principal.
+func NewVerifier(options ...func(*Verifier)) (*Verifier, gomerr.Gomerr) {
+	v := &Verifier{
+		keys:            make(map[string]interface{}),
+		now:             time.Now,
+		principalClaims: map[string]auth.PrincipalType{"sub": auth.User},
+	}
+
+	for _, option := range options {
+		option(v)
+	}
+
+	if len(v.configErrors) > 0 {
+		return nil, gomerr.Configuration("Invalid JWT verifier configuration").Wrap(gomerr.Batcher(v.configErrors))
+	} else if len(v.keys) == 0 {
+		return nil, gomerr.Configuration("No JWT verification keys provided")
+	}
+
+	return v, nil
+}
+
+// HmacKey adds a shared secret for verifying HS256 tokens. The kid should match the token header's "kid" value, or
+// be empty if tokens don't specify one.
+func HmacKey(kid string, secret []byte) func(*Verifier) {
+	return func(v *Verifier) {
+		v.keys[kid] = secret
+	}
+}
+
+// PublicKey adds an *rsa.PublicKey (for RS256) or *ecdsa.PublicKey (for ES256) for verifying tokens.
+func PublicKey(kid string, key crypto.PublicKey) func(*Verifier) {
+	return func(v *Verifier) {
+		switch k := key.(type) {
+		case *rsa.PublicKey, *ecdsa.PublicKey:
+			v.keys[kid] = k
+		default:
+			v.configErrors = append(v.configErrors, gomerr.Configuration("Unsupported public key type").AddAttribute("Kid", kid))
+		}
+	}
+}
+
+// JwksFile adds the keys from a local JSON Web Key Set file.
+func JwksFile(path string) func(*Verifier) {
+	return func(v *Verifier) {
+		keys, ge := readJwksFile(path)
+		if ge != nil {
+			v.configErrors = append(v.configErrors, ge)
+			return
+		}
+
+		for kid, key := range keys {
+			v.keys[kid] = key
+		}
+	}
+}
+
+// Issuer requires tokens to have the given "iss" claim.
+func Issuer(issuer string) func(*Verifier) {
+	return func(v *Verifier) {
+		v.issuer = issuer
+	}
+}
+
+// Audience requires tokens to include the given value in their "aud" claim.
+func Audience(audience string) func(*Verifier) {
+	return func(v *Verifier) {
+		v.audience = audience
+	}
+}
+
+// Leeway allows for clock skew when checking the "exp" and "nbf" claims.
+func Leeway(leeway time.Duration) func(*Verifier) {
+	return func(v *Verifier) {
+		v.leeway = leeway
+	}
+}
+
+// Clock overrides the function used to get the current time.
+func Clock(now func() time.Time) func(*Verifier) {
+	return func(v *Verifier) {
+		v.now = now
+	}
+}
+
+// PrincipalClaim maps a claim to principals of the given type (e.g. auth.Account, auth.Group, or auth.Role). A claim
+// whose value is an array, or a string of space-separated values, results in a principal per value. Mapping a type
+// to the empty claim removes the mapping for that type.
+func PrincipalClaim(claim string, principalType auth.PrincipalType) func(*Verifier) {
+	return func(v *Verifier) {
+		for c, pt := range v.principalClaims {
+			if pt == principalType {
+				delete(v.principalClaims, c)
+			}
+		}
+
+		if claim != "" {
+			v.principalClaims[claim] = principalType
+		}
+	}
+}
+
+// FieldAccessClaim maps a claim to registered field access principals (see auth.FieldAccessPrincipalsFromClaim). If
+// tenantClaim isn't empty, its value selects the tenant's principal set.
+func FieldAccessClaim(claim, tenantClaim string) func(*Verifier) {
+	return func(v *Verifier) {
+		v.fieldAccessClaim = claim
+		v.tenantClaim = tenantClaim
+	}
+}
+
+// Subject verifies the token and returns an auth.Subject with the principals its claims map to. If no field access
+// principals are found, the subject is given auth.NoFieldAccess.
+func (v *Verifier) Subject(token string) (auth.Subject, Claims, gomerr.Gomerr) {
+	claims, ge := v.Verify(token)
+	if ge != nil {
+		return nil, nil, ge
+	}
+
+	subject := auth.NewSubject()
+	for claim, principalType := range v.principalClaims {
+		for _, id := range claimValues(claims[claim]) {
+			subject.AddPrincipals(auth.NewPrincipal(principalType, id))
+		}
+	}
+
+	if v.fieldAccessClaim != "" {
+		tenant, _ := claims[v.tenantClaim].(string)
+		subject.AddPrincipals(auth.FieldAccessPrincipalsFromClaim(claims[v.fieldAccessClaim], tenant)...)
+	}
+
+	if len(auth.PrincipalsOf(subject, auth.NoFieldAccess.Type())) == 0 {
+		subject.AddPrincipals(auth.NoFieldAccess)
+	}
+
+	return subject, claims, nil
+}
+
+// Verify checks the token's signature and its registered claims, returning all of its claims if valid. Possible errors:
+//
+//	gomerr.BadValueError (MalformedValueType):
+//	    if the token can't be parsed
+//	gomerr.BadValueError (InvalidValueType):
+//	    if the signature, algorithm, key id, issuer, audience, or "nbf" claim isn't valid
+//	gomerr.BadValueError (ExpiredValueType):
+//	    if the token has expired
+func (v *Verifier) Verify(token string) (Claims, gomerr.Gomerr) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, gomerr.MalformedValue(tokenName, nil).WithReason("Token must have three parts")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if ge := decodeSegment(parts[0], &header); ge != nil {
+		return nil, ge
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, gomerr.MalformedValue(tokenName, nil).WithReason("Invalid signature encoding")
+	}
+
+	key, ok := v.keys[header.Kid]
+	if !ok {
+		return nil, gomerr.InvalidValue(tokenName, nil, "known key id").WithReason("Unknown key id: " + header.Kid)
+	}
+
+	if ge := verifySignature(header.Alg, key, parts[0]+"."+parts[1], signature); ge != nil {
+		return nil, ge
+	}
+
+	claims := Claims{}
+	if ge := decodeSegment(parts[1], &claims); ge != nil {
+		return nil, ge
+	}
+
+	if ge := v.validateClaims(claims); ge != nil {
+		return nil, ge
+	}
+
+	return claims, nil
+}
+
+func decodeSegment(segment string, target interface{}) gomerr.Gomerr {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return gomerr.MalformedValue(tokenName, nil).WithReason("Invalid segment encoding")
+	}
+
+	if err = json.Unmarshal(b, target); err != nil {
+		return gomerr.MalformedValue(tokenName, nil).WithReason("Invalid segment content")
+	}
+
+	return nil
+}
+
+func verifySignature(alg string, key interface{}, signed string, signature []byte) gomerr.Gomerr {
+	digest := sha256.Sum256([]byte(signed))
+
+	var valid bool
+	switch k := key.(type) {
+	case []byte:
+		if alg != HS256 {
+			break
+		}
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signed))
+		valid = hmac.Equal(signature, mac.Sum(nil))
+	case *rsa.PublicKey:
+		if alg != RS256 {
+			break
+		}
+		valid = rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], signature) == nil
+	case *ecdsa.PublicKey:
+		if alg != ES256 || len(signature) != 64 {
+			break
+		}
+		r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+		valid = ecdsa.Verify(k, digest[:], r, s)
+	}
+
+	if !valid {
+		return gomerr.InvalidValue(tokenName, nil, "valid signature").WithReason("Signature verification failed for algorithm: " + alg)
+	}
+
+	return nil
+}
+
+func (v *Verifier) validateClaims(claims Claims) gomerr.Gomerr {
+	now := v.now()
+
+	if exp, ok := numericDate(claims["exp"]); ok && now.After(exp.Add(v.leeway)) {
+		return gomerr.ValueExpired(tokenName, exp)
+	}
+
+	if nbf, ok := numericDate(claims["nbf"]); ok && now.Add(v.leeway).Before(nbf) {
+		return gomerr.InvalidValue(tokenName, nil, "token in effect").WithReason("Token not valid before: " + nbf.String())
+	}
+
+	if v.issuer != "" && claims["iss"] != v.issuer {
+		return gomerr.InvalidValue(tokenName, nil, v.issuer).WithReason("Unexpected issuer")
+	}
+
+	if v.audience != "" {
+		var found bool
+		for _, aud := range claimValues(claims["aud"]) {
+			if aud == v.audience {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return gomerr.InvalidValue(tokenName, nil, v.audience).WithReason("Unexpected audience")
+		}
+	}
+
+	return nil
+}
+
+func numericDate(value interface{}) (time.Time, bool) {
+	f, ok := value.(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	return time.Unix(int64(f), 0), true
+}
+
+func claimValues(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,107 @@
+package jwt_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jt0/gomer/_test/assert"
+	"github.com/jt0/gomer/auth"
+	"github.com/jt0/gomer/auth/jwt"
+	"github.com/jt0/gomer/gomerr"
+)
+
+var b64 = base64.RawURLEncoding
+
+func sign(t *testing.T, alg, kid string, key interface{}, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := b64.EncodeToString(header) + "." + b64.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		var err error
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		assert.Success(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		assert.Success(t, err)
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+
+	return signed + "." + b64.EncodeToString(signature)
+}
+
+func TestVerifier(t *testing.T) {
+	secret := []byte("shhh")
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Success(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Success(t, err)
+
+	jwksPath := filepath.Join(t.TempDir(), "jwks.json")
+	jwks, _ := json.Marshal(map[string]interface{}{"keys": []map[string]string{{
+		"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64.EncodeToString(ecKey.X.Bytes()), "y": b64.EncodeToString(ecKey.Y.Bytes()),
+	}}})
+	assert.Success(t, os.WriteFile(jwksPath, jwks, 0600))
+
+	editor := auth.NewFieldAccessPrincipal("editor")
+	auth.RegisterFieldAccessPrincipals(editor)
+
+	verifier, ge := jwt.NewVerifier(
+		jwt.HmacKey("hs", secret),
+		jwt.PublicKey("rs", &rsaKey.PublicKey),
+		jwt.JwksFile(jwksPath),
+		jwt.Issuer("gomer"),
+		jwt.PrincipalClaim("groups", auth.Group),
+		jwt.FieldAccessClaim("roles", ""),
+	)
+	assert.Success(t, ge)
+
+	exp := float64(time.Now().Add(time.Hour).Unix())
+	claims := map[string]interface{}{"iss": "gomer", "sub": "u1", "exp": exp, "groups": []string{"a", "b"}, "roles": "editor"}
+
+	for _, tt := range []struct {
+		alg, kid string
+		key      interface{}
+	}{{jwt.HS256, "hs", secret}, {jwt.RS256, "rs", rsaKey}, {jwt.ES256, "ec", ecKey}} {
+		t.Run(tt.alg, func(t *testing.T) {
+			subject, _, ge := verifier.Subject(sign(t, tt.alg, tt.kid, tt.key, claims))
+			assert.Success(t, ge)
+			assert.Equals(t, "u1", subject.Principal(auth.User).Id())
+			assert.Equals(t, 2, len(auth.PrincipalsOf(subject, auth.Group)))
+			assert.Equals(t, editor, subject.Principal(editor.Type()))
+		})
+	}
+
+	expired := map[string]interface{}{"iss": "gomer", "sub": "u1", "exp": float64(time.Now().Add(-time.Hour).Unix())}
+	_, ge = verifier.Verify(sign(t, jwt.HS256, "hs", secret, expired))
+	assert.ErrorType(t, ge, &gomerr.BadValueError{}, "Expected expired token to fail")
+	assert.Equals(t, gomerr.ExpiredValueType, ge.(*gomerr.BadValueError).Type)
+
+	_, ge = verifier.Verify("not-a-token")
+	assert.Equals(t, gomerr.MalformedValueType, ge.(*gomerr.BadValueError).Type)
+
+	// An RS256 header using the HMAC key's id must not verify
+	_, ge = verifier.Verify(sign(t, jwt.RS256, "hs", rsaKey, claims))
+	assert.Equals(t, gomerr.InvalidValueType, ge.(*gomerr.BadValueError).Type)
+
+	_, ge = verifier.Verify(sign(t, jwt.HS256, "hs", []byte("wrong"), claims))
+	assert.Equals(t, gomerr.InvalidValueType, ge.(*gomerr.BadValueError).Type)
+}
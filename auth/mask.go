@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/jt0/gomer/gomerr"
+)
+
+const (
+	maskKey                = "mask" // Reserved in place of a tenant name, e.g. "rwm-|mask=last4"
+	defaultMaskPlaceholder = "****"
+	maskChar               = "*"
+	visibleSuffixLength    = 4
+	hashLength             = 12
+)
+
+var (
+	placeholderRegexp = regexp.MustCompile(`^placeholder\((.*)\)$`)
+	hashMaskKey       []byte
+)
+
+// SetHashMaskKey sets the secret key used by the 'hash' mask. The mask is a keyed hash (HMAC-SHA256) so that, without
+// the key, a masked value can't be recovered by hashing guesses of it. The key should be at least 32 random bytes and
+// must be set before any field using the mask is processed.
+func SetHashMaskKey(key []byte) {
+	hashMaskKey = append([]byte(nil), key...)
+}
+
+func buildAccessRegexp(extraChars string) *regexp.Regexp {
+	return regexp.MustCompile("(r|m|-)(w|c|u|p|-)([" + regexp.QuoteMeta(extraChars) + "]*)")
+}
+
+func maskFor(spec string) (func(string) string, gomerr.Gomerr) {
+	switch spec {
+	case "last4":
+		return lastFourMask, nil
+	case "hash":
+		if len(hashMaskKey) == 0 {
+			return nil, gomerr.Configuration("The 'hash' mask requires a key (see SetHashMaskKey)")
+		}
+		return hashMask(hashMaskKey), nil
+	}
+
+	if groups := placeholderRegexp.FindStringSubmatch(spec); groups != nil {
+		return placeholderMask(groups[1]), nil
+	}
+
+	return nil, gomerr.Configuration("Unrecognized 'access' mask").AddAttribute("Mask", spec)
+}
+
+func lastFourMask(value string) string {
+	runes := []rune(value)
+	if len(runes) <= visibleSuffixLength {
+		return strings.Repeat(maskChar, len(runes))
+	}
+
+	return strings.Repeat(maskChar, len(runes)-visibleSuffixLength) + string(runes[len(runes)-visibleSuffixLength:])
+}
+
+func hashMask(key []byte) func(string) string {
+	return func(value string) string {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(value))
+		return hex.EncodeToString(mac.Sum(nil))[:hashLength]
+	}
+}
+
+func placeholderMask(placeholder string) func(string) string {
+	return func(string) string {
+		return placeholder
+	}
+}
+
+// maskValue replaces a string (or *string) field's value with its masked form. Empty values are left as is so that
+// a masked field still indicates whether or not a value exists.
+func maskValue(fv reflect.Value, mask func(string) string) {
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() || fv.Elem().String() == "" {
+			return
+		}
+		masked := mask(fv.Elem().String())
+		fv.Set(reflect.ValueOf(&masked).Convert(fv.Type()))
+		return
+	}
+
+	if fv.String() != "" {
+		fv.SetString(mask(fv.String()))
+	}
+}
@@ -9,30 +9,69 @@ type Subject interface {
 	Release(errored bool) gomerr.Gomerr
 }
 
+// MultiPrincipalSubject is implemented by Subjects that can hold more than one Principal of a given type, such as a
+// user with several field access principals (e.g. both "editor" and "billing"). Permission checks combine them.
+type MultiPrincipalSubject interface {
+	Subject
+	Principals(principalType PrincipalType) []Principal
+}
+
+// PrincipalsOf returns the subject's principals of the given type.
+func PrincipalsOf(subject Subject, principalType PrincipalType) []Principal {
+	if subject == nil {
+		return nil
+	}
+
+	if multi, ok := subject.(MultiPrincipalSubject); ok {
+		return multi.Principals(principalType)
+	}
+
+	if principal := subject.Principal(principalType); principal != nil {
+		return []Principal{principal}
+	}
+
+	return nil
+}
+
 type basicSubject struct {
-	principals map[PrincipalType]Principal
+	principals map[PrincipalType][]Principal
 }
 
 func NewSubject(principals ...Principal) *basicSubject {
-	principalMap := make(map[PrincipalType]Principal, len(principals))
+	b := &basicSubject{make(map[PrincipalType][]Principal, len(principals))}
+	b.AddPrincipals(principals...)
 
+	return b
+}
+
+// AddPrincipals adds principals to the subject. A subject can have multiple principals of the same type.
+func (b *basicSubject) AddPrincipals(principals ...Principal) {
 	for _, principal := range principals {
-		principalMap[principal.Type()] = principal
+		b.principals[principal.Type()] = append(b.principals[principal.Type()], principal)
 	}
-
-	return &basicSubject{principalMap}
 }
 
+// Principal returns the first principal of the given type, or nil if there is none.
 func (b *basicSubject) Principal(principalType PrincipalType) Principal {
+	if principals := b.principals[principalType]; len(principals) > 0 {
+		return principals[0]
+	}
+
+	return nil
+}
+
+func (b *basicSubject) Principals(principalType PrincipalType) []Principal {
 	return b.principals[principalType]
 }
 
 func (b *basicSubject) Release(errored bool) gomerr.Gomerr {
 	errors := make([]gomerr.Gomerr, 0)
-	for _, principal := range b.principals {
-		ge := principal.Release(errored)
-		if ge != nil {
-			errors = append(errors, ge)
+	for _, principals := range b.principals {
+		for _, principal := range principals {
+			ge := principal.Release(errored)
+			if ge != nil {
+				errors = append(errors, ge)
+			}
 		}
 	}
 
@@ -58,3 +97,25 @@ type Principal interface {
 	Type() PrincipalType
 	Release(errored bool) gomerr.Gomerr
 }
+
+type basicPrincipal struct {
+	id            string
+	principalType PrincipalType
+}
+
+// NewPrincipal returns a Principal of the given type and id, e.g. one derived from an authentication token's claims.
+func NewPrincipal(principalType PrincipalType, id string) Principal {
+	return basicPrincipal{id, principalType}
+}
+
+func (p basicPrincipal) Id() string {
+	return p.id
+}
+
+func (p basicPrincipal) Type() PrincipalType {
+	return p.principalType
+}
+
+func (p basicPrincipal) Release(bool) gomerr.Gomerr {
+	return nil
+}
@@ -1,6 +1,9 @@
 package bind
 
 import (
+	"unicode"
+	"unicode/utf8"
+
 	"github.com/jt0/gomer/structs"
 )
 
@@ -20,14 +23,15 @@ type casingFn func(s string) string
 var (
 	PascalCase casingFn = func(fieldName string) string { return fieldName } // Exported fields are already PascalCase
 	CamelCase  casingFn = func(fieldName string) string {
-		// NB: only supports field names with an initial ASCII character
-		firstChar := fieldName[0]
-		if 'A' <= firstChar && firstChar <= 'Z' {
-			firstChar += 'a' - 'A'
+		r, size := utf8.DecodeRuneInString(fieldName)
+		if size == 0 {
+			return fieldName
 		}
-		return string(firstChar) + fieldName[1:]
+		return string(unicode.ToLower(r)) + fieldName[size:]
 	}
-	// Feature:p2 support case insensitive. Additionally consider support matching output case to input
+	SnakeCase casingFn = func(fieldName string) string { return separatedLowerCase(fieldName, '_') } // e.g. UserID -> user_id
+	KebabCase casingFn = func(fieldName string) string { return separatedLowerCase(fieldName, '-') } // e.g. UserID -> user-id
+	// Feature:p2 consider support matching output case to input
 )
 
 type Configuration struct {
@@ -38,13 +42,21 @@ type Configuration struct {
 	emptyValue string
 
 	// Specifies the casing used for inbound and outbound data. Simplifies the naming configuration if the only
-	// difference between a data attribute and struct field name is the casing.
-	// TODO:p3 I can imagine a situation where the casing might vary depending on where it's coming from or going to,
-	//  so perhaps should be map[string]FieldCaseType where the key = scope...
-	toCase *casingFn
+	// difference between a data attribute and struct field name is the casing. The casing can vary depending on where
+	// the data is coming from or going to by overriding it for specific scopes.
+	toCase       *casingFn
+	scopedToCase map[string]*casingFn
+
+	// If true, inbound data attributes are matched to names regardless of case when there isn't an exact match.
+	caseInsensitive bool
 
 	extension ExtensionProvider
 
+	// If strictInput is true, or the scope is in strictInputScopes, input values that aren't bound to a field are
+	// rejected rather than ignored (see VerifyInput).
+	strictInput       bool
+	strictInputScopes map[string]bool
+
 	// TODO:p2 RawBytesBindingDirective string
 }
 
@@ -60,7 +72,6 @@ func NewConfiguration(options ...func(*Configuration)) Configuration {
 		emptyDirective: skipField,
 		emptyValue:     omitEmpty,
 		toCase:         &PascalCase,
-		// strictMode: false,  // true if should fail on extra input values, false otherwise
 	}
 
 	return bc.withOptions(options...)
@@ -94,6 +105,74 @@ func CamelCaseData(c *Configuration) {
 	c.toCase = &CamelCase
 }
 
+func SnakeCaseData(c *Configuration) {
+	c.toCase = &SnakeCase
+}
+
+func KebabCaseData(c *Configuration) {
+	c.toCase = &KebabCase
+}
+
+// ScopedDataCase overrides the data casing when a tool is applied with one of the given scopes. For example, an
+// application's public API can use snake_case while the same structs are bound to camelCase event payloads:
+//
+//	bind.NewConfiguration(bind.CamelCaseData, bind.ScopedDataCase(bind.SnakeCase, "api"))
+func ScopedDataCase(toCase casingFn, scopes ...string) func(*Configuration) {
+	return func(c *Configuration) {
+		scopedToCase := make(map[string]*casingFn, len(c.scopedToCase)+len(scopes))
+		for scope, scopeToCase := range c.scopedToCase {
+			scopedToCase[scope] = scopeToCase
+		}
+		for _, scope := range scopes {
+			scopedToCase[scope] = &toCase
+		}
+		c.scopedToCase = scopedToCase
+	}
+}
+
+// CaseInsensitiveData allows an InTool to match inbound data attributes to names regardless of case (e.g. "userId"
+// matches "UserID") when there isn't an exact match.
+func CaseInsensitiveData(c *Configuration) {
+	c.caseInsensitive = true
+}
+
+// casedNames returns the name with the configured casing, along with the name for each scope that overrides it.
+func (bc Configuration) casedNames(name string) (string, map[string]string) {
+	var scopedNames map[string]string
+	if len(bc.scopedToCase) > 0 {
+		scopedNames = make(map[string]string, len(bc.scopedToCase))
+		for scope, toCase := range bc.scopedToCase {
+			scopedNames[scope] = (*toCase)(name)
+		}
+	}
+
+	return (*bc.toCase)(name), scopedNames
+}
+
+// StrictInput causes an InTool to reject input values that aren't bound to any field (e.g. a misspelled or unsupported
+// attribute name) in every scope.
+func StrictInput(c *Configuration) {
+	c.strictInput = true
+}
+
+// StrictInputForScopes is like StrictInput, but only applies when the tool is applied with one of the given scopes.
+func StrictInputForScopes(scopes ...string) func(*Configuration) {
+	return func(c *Configuration) {
+		strictInputScopes := make(map[string]bool, len(c.strictInputScopes)+len(scopes))
+		for scope := range c.strictInputScopes {
+			strictInputScopes[scope] = true
+		}
+		for _, scope := range scopes {
+			strictInputScopes[scope] = true
+		}
+		c.strictInputScopes = strictInputScopes
+	}
+}
+
+func (bc Configuration) strictFor(scope string) bool {
+	return bc.strictInput || bc.strictInputScopes[scope]
+}
+
 type ExtensionProvider interface {
 	structs.ApplierProvider
 	Type() string
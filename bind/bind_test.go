@@ -5,6 +5,7 @@ import (
 
 	"github.com/jt0/gomer/_test/assert"
 	"github.com/jt0/gomer/bind"
+	"github.com/jt0/gomer/structs"
 )
 
 func TestCopyWithOptions(t *testing.T) {
@@ -13,3 +14,44 @@ func TestCopyWithOptions(t *testing.T) {
 
 	assert.NotEquals(t, b1, b2)
 }
+
+func TestCasing(t *testing.T) {
+	for fieldName, expected := range map[string][3]string{
+		"Name":         {"name", "name", "name"},
+		"UserID":       {"userID", "user_id", "user-id"},
+		"HTTPServerID": {"hTTPServerID", "http_server_id", "http-server-id"},
+		"Address2Line": {"address2Line", "address2_line", "address2-line"},
+		"ÉtatCivil":    {"étatCivil", "état_civil", "état-civil"},
+	} {
+		assert.Equals(t, expected[0], bind.CamelCase(fieldName))
+		assert.Equals(t, expected[1], bind.SnakeCase(fieldName))
+		assert.Equals(t, expected[2], bind.KebabCase(fieldName))
+	}
+}
+
+type Cased struct {
+	UserID    string `in:"+" out:"+"`
+	FirstName string `in:"+" out:"+"`
+}
+
+func TestScopedAndCaseInsensitiveData(t *testing.T) {
+	config := bind.NewConfiguration(bind.EmptyDirectiveIncludesField, bind.CamelCaseData, bind.ScopedDataCase(bind.SnakeCase, "api"), bind.CaseInsensitiveData)
+	inTool := bind.NewInTool(config, structs.StructTagDirectiveProvider{TagKey: "in"})
+	outTool := bind.NewOutTool(config, structs.StructTagDirectiveProvider{TagKey: "out"})
+
+	var event Cased
+	assert.Success(t, bind.In(map[string]interface{}{"userID": "u1", "FIRSTNAME": "f1"}, &event, inTool))
+	assert.Equals(t, Cased{UserID: "u1", FirstName: "f1"}, event)
+
+	var api Cased
+	assert.Success(t, bind.In(map[string]interface{}{"user_id": "u2", "first_name": "f2"}, &api, inTool, structs.ToolContextWithScope("api")))
+	assert.Equals(t, Cased{UserID: "u2", FirstName: "f2"}, api)
+
+	out, ge := bind.Out(&api, outTool, structs.ToolContextWithScope("api"))
+	assert.Success(t, ge)
+	assert.Equals(t, map[string]interface{}{"user_id": "u2", "first_name": "f2"}, out)
+
+	out, ge = bind.Out(&event, outTool)
+	assert.Success(t, ge)
+	assert.Equals(t, map[string]interface{}{"userID": "u1", "firstName": "f1"}, out)
+}
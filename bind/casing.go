@@ -0,0 +1,30 @@
+package bind
+
+import (
+	"strings"
+	"unicode"
+)
+
+// separatedLowerCase converts a PascalCase (or camelCase) name to lower case with the separator between its words. A
+// word starts at an upper case letter that follows a lower case letter or digit, or that is followed by a lower case
+// letter, so acronyms are kept together (e.g. "HTTPServerID" -> "http_server_id").
+func separatedLowerCase(name string, separator rune) string {
+	runes := []rune(name)
+
+	var sb strings.Builder
+	sb.Grow(len(name) + 4)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				prev := runes[i-1]
+				if unicode.IsLower(prev) || unicode.IsDigit(prev) || unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+					sb.WriteRune(separator)
+				}
+			}
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+
+	return sb.String()
+}
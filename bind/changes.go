@@ -0,0 +1,77 @@
+package bind
+
+import (
+	"github.com/jt0/gomer/structs"
+)
+
+const changesKey = "$_gomer_bind_changes"
+
+type changes struct {
+	set      map[string]bool
+	removed  map[string]bool
+	replaced map[string]string
+}
+
+// Null is an input value that sets a field to its zero value. When changes are tracked, a field whose input value is
+// nil is removed, whereas one whose value is Null is set (e.g. to a JSON null). See TrackedChanges.
+var Null = null{}
+
+type null struct{}
+
+// Replacement is an input value that replaces a field's current one (e.g. from a JSON Patch 'replace' operation). The
+// field is bound to Value, and when changes are tracked, it's also recorded as replaced. See TrackedReplacements.
+type Replacement struct {
+	Value interface{}
+}
+
+// AddChangeTrackingToContext has an InTool record the fields whose input values were present when it's applied. This
+// lets an update that's bound from a patch (e.g. a JSON merge patch) distinguish a value that was explicitly set to
+// its zero value (or removed with a null) from one that wasn't provided. See TrackedChanges.
+//
+// Only the top-level fields are tracked. A nested struct, slice, or map value is treated as a change to the field that
+// contains it.
+func AddChangeTrackingToContext(tcs ...*structs.ToolContext) *structs.ToolContext {
+	return structs.EnsureContext(tcs...).Put(changesKey, &changes{set: make(map[string]bool), removed: make(map[string]bool), replaced: make(map[string]string)})
+}
+
+// TrackedChanges returns the names of the fields whose input values were present (set) or null (removed) when the
+// InTool was applied. Both are nil if change tracking wasn't added to the context (see AddChangeTrackingToContext).
+func TrackedChanges(tc *structs.ToolContext) (set map[string]bool, removed map[string]bool) {
+	if c, ok := tc.Get(changesKey).(*changes); ok && c != nil {
+		return c.set, c.removed
+	}
+	return nil, nil
+}
+
+// TrackedReplacements returns the fields whose input values were a Replacement when the InTool was applied, keyed by
+// the names of the inputs. It's nil if change tracking wasn't added to the context (see AddChangeTrackingToContext).
+func TrackedReplacements(tc *structs.ToolContext) map[string]string {
+	if c, ok := tc.Get(changesKey).(*changes); ok && c != nil {
+		return c.replaced
+	}
+	return nil
+}
+
+// trackChange records the field as set or removed, and whether its input (from source) replaced it, if changes are
+// being tracked. It returns a function that restores
+// the tracking once the value (including any nested values) has been bound.
+func trackChange(field, source string, removed, replaced bool, tc *structs.ToolContext) func() {
+	c, ok := tc.Get(changesKey).(*changes)
+	if !ok || c == nil {
+		return func() {}
+	}
+
+	if removed {
+		c.removed[field] = true
+	} else {
+		c.set[field] = true
+	}
+	if replaced {
+		c.replaced[source] = field
+	}
+
+	tc.Put(changesKey, nil) // nested values aren't tracked
+	return func() {
+		tc.Put(changesKey, c)
+	}
+}
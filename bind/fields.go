@@ -0,0 +1,216 @@
+package bind
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/jt0/gomer/gomerr"
+	"github.com/jt0/gomer/structs"
+)
+
+const (
+	FieldsKey = "$_gomer_bind_fields"
+
+	fieldsProbeKey = "$_gomer_bind_fields_probe"
+
+	fieldPathSeparator = "."
+)
+
+// fieldSelection is a node in the tree of selected output names. A node without children selects all of the value's
+// output (e.g. every field of a struct), while one with children selects only those names.
+type fieldSelection struct {
+	children map[string]*fieldSelection
+	matched  bool   // the name matched an output value
+	field    string // the name of the struct field whose value's output the name matched (see SelectedFieldNames)
+	visited  bool   // the selection was applied to a value's output
+}
+
+// AddFieldSelectionToContext limits the output of an OutTool to the selected fields. Each field is an output name
+// (i.e. after any 'out' renaming and casing), with '.' separating the names of nested values (e.g. "owner.email").
+// Selecting a name without any nested names includes all of its value. Values that aren't produced by name (e.g.
+// static values or headers) aren't affected.
+//
+// After the tool has been applied, VerifyFieldSelection can be used to check that each selected name was recognized.
+func AddFieldSelectionToContext(fields []string, tcs ...*structs.ToolContext) *structs.ToolContext {
+	tc := structs.EnsureContext(tcs...)
+	if len(fields) == 0 {
+		return tc
+	}
+
+	root := &fieldSelection{}
+	for _, field := range fields {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+
+		node := root
+		for _, name := range strings.Split(field, fieldPathSeparator) {
+			if node.children == nil {
+				node.children = make(map[string]*fieldSelection)
+			}
+			child, ok := node.children[name]
+			if !ok {
+				child = &fieldSelection{}
+				node.children[name] = child
+			}
+			node = child
+		}
+	}
+
+	return tc.Put(FieldsKey, root)
+}
+
+// VerifyFieldSelection returns a gomerr.BadValueError if any of the selected fields (see AddFieldSelectionToContext)
+// didn't match an output name.
+func VerifyFieldSelection(tc *structs.ToolContext) gomerr.Gomerr {
+	if tc == nil {
+		return nil
+	}
+
+	root, ok := tc.Get(FieldsKey).(*fieldSelection)
+	if !ok || root == nil {
+		return nil
+	}
+
+	var unknown []string
+	root.collectUnknown("", &unknown)
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	sort.Strings(unknown)
+	return gomerr.InvalidValue("fields", strings.Join(unknown, ","), "known field names").WithReason("Unknown field name(s)")
+}
+
+// VerifyFieldSelectionForType is like VerifyFieldSelection, but checks the selected fields (see
+// AddFieldSelectionToContext) against the output names of t's fields rather than those of a value. This lets a request
+// with an unknown field be rejected before it's acted upon. The names of map entries can't be known without a value,
+// so any name selected within a map is accepted.
+func VerifyFieldSelectionForType(t reflect.Type, outTool *structs.Tool, tc *structs.ToolContext) gomerr.Gomerr {
+	if currentFieldSelection(tc) == nil {
+		return nil
+	}
+
+	outData := tc.Get(OutKey)
+	tc.Put(OutKey, make(map[string]interface{})).Put(fieldsProbeKey, make(map[probedType]bool))
+	defer func() {
+		tc.Put(OutKey, outData).Put(fieldsProbeKey, nil)
+	}()
+
+	if ge := structs.ApplyTools(reflect.New(t).Interface(), tc, outTool); ge != nil {
+		return ge
+	}
+
+	return VerifyFieldSelection(tc)
+}
+
+// SelectedFieldNames returns the names of the struct fields whose values produce the top-level selected names, as found
+// by VerifyFieldSelectionForType. This lets a caller read only those fields' values (see data.AttributeSelector). It
+// returns nil if there's no selection or if any of the names is produced some other way (e.g. by a map's entry), since
+// then the fields it depends on can't be known.
+func SelectedFieldNames(tc *structs.ToolContext) []string {
+	root := currentFieldSelection(tc)
+	if root == nil || len(root.children) == 0 {
+		return nil
+	}
+
+	fields := make([]string, 0, len(root.children))
+	for _, child := range root.children {
+		if child.field == "" {
+			return nil
+		}
+		fields = append(fields, child.field)
+	}
+
+	sort.Strings(fields)
+	return fields
+}
+
+func probingFields(tc *structs.ToolContext) bool {
+	return tc.Get(fieldsProbeKey) != nil
+}
+
+// probedType is a type whose output names have been probed for a selection.
+type probedType struct {
+	t         reflect.Type
+	selection *fieldSelection
+}
+
+// probeNeeded returns whether a value of type t has output names that need to be probed for the selection. None do if
+// all of its output is selected, or if t has already been probed for the same selection.
+func probeNeeded(t reflect.Type, selection *fieldSelection, tc *structs.ToolContext) bool {
+	if selection == nil {
+		return false
+	}
+
+	probed := tc.Get(fieldsProbeKey).(map[probedType]bool)
+	if probed[probedType{t, selection}] {
+		return false
+	}
+	probed[probedType{t, selection}] = true
+
+	return true
+}
+
+// acceptAny marks each of the selection's names as matched.
+func (fs *fieldSelection) acceptAny() {
+	if fs == nil {
+		return
+	}
+
+	fs.visited = true
+	for _, child := range fs.children {
+		child.matched = true
+	}
+}
+
+// matchedByField records the struct field whose value's output has the name if the name is selected.
+func (fs *fieldSelection) matchedByField(name string, field string) {
+	if fs == nil {
+		return
+	}
+	if child, ok := fs.children[name]; ok {
+		child.field = field
+	}
+}
+
+func (fs *fieldSelection) collectUnknown(path string, unknown *[]string) {
+	if !fs.visited {
+		return // the selection was never applied (e.g. the parent value was empty), so nothing to check
+	}
+
+	for name, child := range fs.children {
+		if !child.matched {
+			*unknown = append(*unknown, path+name)
+			continue
+		}
+		child.collectUnknown(path+name+fieldPathSeparator, unknown)
+	}
+}
+
+// selected returns whether the name is included in the selection, and, if so, the selection for its value (nil if all
+// of it is included).
+func (fs *fieldSelection) selected(name string) (bool, *fieldSelection) {
+	if fs == nil || fs.children == nil {
+		return true, nil
+	}
+
+	fs.visited = true
+
+	child, ok := fs.children[name]
+	if !ok {
+		return false, nil
+	}
+	child.matched = true
+
+	if child.children == nil {
+		return true, nil
+	}
+	return true, child
+}
+
+func currentFieldSelection(tc *structs.ToolContext) *fieldSelection {
+	fs, _ := tc.Get(FieldsKey).(*fieldSelection)
+	return fs
+}
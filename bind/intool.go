@@ -2,7 +2,7 @@ package bind
 
 import (
 	"reflect"
-	"time"
+	"strings"
 
 	"github.com/jt0/gomer/flect"
 	"github.com/jt0/gomer/gomerr"
@@ -11,8 +11,16 @@ import (
 
 var DefaultInTool = NewInTool(NewConfiguration(), structs.StructTagDirectiveProvider{"in"})
 
+// In binds the data to v using the inTool. If the tool is strict (see StrictInput), an error is also returned for each
+// value in the data that isn't bound to one of v's fields.
 func In(data map[string]interface{}, v interface{}, inTool *structs.Tool, optional ...*structs.ToolContext) gomerr.Gomerr {
-	return structs.ApplyTools(v, structs.EnsureContext(optional...).Put(InKey, data), inTool)
+	tc := structs.EnsureContext(optional...).Put(InKey, data).Put(usedInputKey, nil)
+	ge := structs.ApplyTools(v, tc, inTool)
+	if !strictInput(inTool, tc) {
+		return ge
+	}
+
+	return withUnknownInput(ge, tc)
 }
 
 // NewInTool
@@ -49,7 +57,7 @@ func (ap inApplierProvider) Applier(st reflect.Type, sf reflect.StructField, dir
 	}
 
 	if directive == includeField || directive == "" { // b.emptyDirective must be 'includeField' otherwise would have returned above
-		return inApplier{(*ap.toCase)(sf.Name), ap.tool}, nil
+		return ap.inApplier(sf.Name, sf.Name), nil
 	} else if firstChar := directive[0]; firstChar == '=' {
 		return structs.ValueApplier{directive[1:]}, nil // don't include the '='
 	} else if firstChar == '$' {
@@ -62,17 +70,32 @@ func (ap inApplierProvider) Applier(st reflect.Type, sf reflect.StructField, dir
 		}
 	}
 
-	return inApplier{(*ap.toCase)(directive), ap.tool}, nil
+	return ap.inApplier(sf.Name, directive), nil
+}
+
+func (ap inApplierProvider) inApplier(field string, name string) inApplier {
+	source, scopedSources := ap.casedNames(name)
+	return inApplier{field: field, source: source, scopedSources: scopedSources, tool: ap.tool}
 }
 
 type inApplier struct {
-	source string
-	tool   *structs.Tool
+	field         string // the name of the struct field being bound (see AddChangeTrackingToContext)
+	source        string
+	scopedSources map[string]string // scope -> source, for scopes with their own data casing
+	tool          *structs.Tool
+}
+
+// forScope returns an inApplier with the source for the scope.
+func (a inApplier) forScope(scope string) inApplier {
+	if source, ok := a.scopedSources[scope]; ok {
+		return inApplier{field: a.field, source: source, tool: a.tool}
+	}
+	return a
 }
 
 var (
-	timeType      = reflect.TypeOf((*time.Time)(nil)).Elem()
 	byteSliceType = reflect.TypeOf((*[]uint8)(nil)).Elem()
+	stringType    = reflect.TypeOf("")
 	// uint8SliceType = reflect.TypeOf((*[]uint8)(nil)).Elem()
 )
 
@@ -82,28 +105,53 @@ func (a inApplier) Apply(sv reflect.Value, fv reflect.Value, tc *structs.ToolCon
 		return nil
 	}
 
+	a = a.forScope(tc.Scope())
+
 	imv := reflect.ValueOf(inData)
 	if imv.Kind() != reflect.Map {
 		return gomerr.Unprocessable("Expected data map", inData).AddAttribute("Source", a.source)
 	}
 
-	mv := imv.MapIndex(reflect.ValueOf(a.source))
-	if !mv.IsValid() || mv.IsNil() {
+	config := inConfiguration(a.tool)
+	key, mv := a.source, imv.MapIndex(reflect.ValueOf(a.source))
+	if !mv.IsValid() && config.caseInsensitive {
+		key, mv = lookupFold(imv, a.source)
+	}
+
+	strict := config.strictFor(tc.Scope())
+	if strict {
+		MarkInputUsed(key, tc)
+	}
+
+	if !mv.IsValid() {
 		return nil
 	}
+
 	value := mv.Interface()
+	replacement, replaced := value.(Replacement)
+	if replaced {
+		value = replacement.Value
+	}
+	defer trackChange(a.field, key, value == nil, replaced, tc)()
+
+	if value == nil {
+		return nil
+	} else if value == Null {
+		fv.Set(reflect.Zero(fv.Type()))
+		return nil
+	}
 
 	switch fvt := fv.Type(); fv.Kind() {
 	case reflect.Struct:
 		vt := reflect.TypeOf(value)
 
-		// Time structs are a special case
-		if stringValue, ok := value.(string); ok && fvt == timeType {
-			t, err := time.Parse(time.RFC3339Nano, stringValue)
-			if err != nil {
-				return gomerr.BadValue(gomerr.GenericBadValueType, a.source, stringValue).AddAttribute("Expected", "RFC3339-formatted string")
+		// Struct types with a converter (e.g. time.Time) are set like other values
+		if converted, ok, ge := flect.Convert(value, fvt); ge != nil {
+			return ge.AddAttributes("Source", a.source)
+		} else if ok {
+			if ge = flect.SetValue(fv, converted); ge != nil {
+				return ge.AddAttributes("Source", a.source)
 			}
-			fv.Set(reflect.ValueOf(t)) // TODO: use flect.SetValue instead?
 			return nil
 		} else if fvt == vt {
 			return flect.SetValue(fv, value)
@@ -115,10 +163,28 @@ func (a inApplier) Apply(sv reflect.Value, fv reflect.Value, tc *structs.ToolCon
 
 		tc.Put(InKey, value)
 		defer tc.Put(InKey, inData)
-		if ge := structs.ApplyTools(fv, tc, a.tool); ge != nil {
+		if !strict {
+			if ge := structs.ApplyTools(fv, tc, a.tool); ge != nil {
+				return ge.AddAttribute("Source", a.source)
+			}
+			return nil
+		}
+
+		defer descendInput(key, tc)()
+		if ge := withUnknownInput(structs.ApplyTools(fv, tc, a.tool), tc); ge != nil {
 			return ge.AddAttribute("Source", a.source)
 		}
 	case reflect.Slice:
+		// Slice types with a converter (e.g. net.IP) are set like other values
+		if converted, ok, ge := flect.Convert(value, fvt); ge != nil {
+			return ge.AddAttributes("Source", a.source)
+		} else if ok {
+			if ge = flect.SetValue(fv, converted); ge != nil {
+				return ge.AddAttributes("Source", a.source)
+			}
+			return nil
+		}
+
 		// []byte types are a special case
 		// TODO: should treat other primitive types this way?
 		if fvt == byteSliceType {
@@ -143,7 +209,7 @@ func (a inApplier) Apply(sv reflect.Value, fv reflect.Value, tc *structs.ToolCon
 		defer tc.Put(InKey, inData)
 		for i := 0; i < sliceLen; i++ {
 			tc.Put(InKey, map[string]interface{}{a.source: sliceData[i]})
-			if ge := a.Apply(sv, fv.Index(i), tc); ge != nil {
+			if ge := a.applyElement(sv, fv.Index(i), tc, strict, i); ge != nil {
 				return ge.AddAttribute("Index", i)
 			}
 		}
@@ -155,7 +221,7 @@ func (a inApplier) Apply(sv reflect.Value, fv reflect.Value, tc *structs.ToolCon
 		for iter.Next() {
 			tc.Put(InKey, map[string]interface{}{a.source: iter.Value().Interface()})
 			mapElem := reflect.New(fvt.Elem()).Elem()
-			if ge := a.Apply(sv, mapElem, tc); ge != nil {
+			if ge := a.applyElement(sv, mapElem, tc, strict, iter.Key().String()); ge != nil {
 				return ge.AddAttribute("Key", iter.Key().String())
 			}
 			fv.SetMapIndex(iter.Key(), mapElem)
@@ -179,3 +245,27 @@ func (a inApplier) Apply(sv reflect.Value, fv reflect.Value, tc *structs.ToolCon
 
 	return nil
 }
+
+func (a inApplier) applyElement(sv reflect.Value, ev reflect.Value, tc *structs.ToolContext, strict bool, indexOrKey interface{}) gomerr.Gomerr {
+	if strict {
+		defer inputElement(indexOrKey, tc)()
+	}
+	return a.Apply(sv, ev, tc)
+}
+
+// lookupFold returns the key and value of the map entry whose key matches name regardless of case.
+func lookupFold(mapValue reflect.Value, name string) (string, reflect.Value) {
+	iter := mapValue.MapRange()
+	for iter.Next() {
+		if key := iter.Key(); key.Kind() == reflect.String && strings.EqualFold(key.String(), name) {
+			return key.String(), iter.Value()
+		}
+	}
+
+	return name, reflect.Value{}
+}
+
+func inConfiguration(inTool *structs.Tool) Configuration {
+	ap, _ := inTool.ApplierProvider().(inApplierProvider)
+	return ap.Configuration
+}
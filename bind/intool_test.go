@@ -2,10 +2,18 @@ package bind_test
 
 import (
 	"encoding/json"
+	"math"
+	"net"
+	"sort"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/jt0/gomer/_test/assert"
 	"github.com/jt0/gomer/bind"
+	"github.com/jt0/gomer/flect"
+	"github.com/jt0/gomer/gomerr"
+	"github.com/jt0/gomer/structs"
 )
 
 type Foo struct {
@@ -67,3 +75,120 @@ var in1 = []byte(`
 
 // Lists, maps, structs, pointers, pointer-pointers
 // functions
+
+type Strict struct {
+	Name  string        `in:"+"`
+	Owner *StrictOwner  `in:"+"`
+	Items []StrictOwner `in:"+"`
+	Skip  string        `in:"-"`
+}
+
+type StrictOwner struct {
+	Email string `in:"+"`
+}
+
+func TestStrictInput(t *testing.T) {
+	data := func() map[string]interface{} {
+		return dataFrom(t, []byte(`{"Name": "n", "Nmae": "typo", "Skip": "s", "Owner": {"Email": "e", "Phone": "p"}, "Items": [{"Email": "e"}, {"Emial": "e"}]}`))
+	}
+
+	var lenient Strict
+	assert.Success(t, bind.In(data(), &lenient, bind.DefaultInTool))
+	assert.Equals(t, "e", lenient.Owner.Email)
+
+	strictTool := bind.NewInTool(bind.NewConfiguration(bind.StrictInput), structs.StructTagDirectiveProvider{TagKey: "in"})
+	ge := bind.In(data(), &Strict{}, strictTool)
+	assert.ErrorType(t, ge, &gomerr.BatchError{}, "Expected each unknown field to be rejected")
+
+	var names []string
+	var collect func(gomerr.Gomerr)
+	collect = func(ge gomerr.Gomerr) {
+		if be, ok := ge.(*gomerr.BatchError); ok {
+			for _, e := range be.Errors() {
+				collect(e)
+			}
+		} else if bve, ok := ge.(*gomerr.BadValueError); ok && bve.Type == gomerr.UnknownValueType {
+			names = append(names, bve.Name)
+		}
+	}
+	collect(ge)
+	sort.Strings(names)
+	assert.Equals(t, []string{"Items[1].Emial", "Nmae", "Owner.Phone", "Skip"}, names)
+
+	scopedTool := bind.NewInTool(bind.NewConfiguration(bind.StrictInputForScopes("create")), structs.StructTagDirectiveProvider{TagKey: "in"})
+	assert.Success(t, bind.In(data(), &Strict{}, scopedTool, structs.ToolContextWithScope("update")))
+	assert.ErrorType(t, bind.In(data(), &Strict{}, scopedTool, structs.ToolContextWithScope("create")), &gomerr.BatchError{}, "Expected strict scope to reject unknown fields")
+}
+
+type Status string
+
+func (Status) Values() []string {
+	return []string{"active", "disabled"}
+}
+
+type Account struct {
+	Status  Status   `in:"+"`
+	History []Status `in:"+"`
+}
+
+func TestEnumInput(t *testing.T) {
+	var a Account
+	assert.Success(t, bind.In(map[string]interface{}{"Status": "active", "History": []interface{}{"disabled"}}, &a, bind.DefaultInTool))
+	assert.Equals(t, Account{Status: "active", History: []Status{"disabled"}}, a)
+
+	ge := bind.In(map[string]interface{}{"Status": "deleted"}, &Account{}, bind.DefaultInTool)
+	assert.ErrorType(t, ge, &gomerr.BadValueError{}, "Expected an unknown enum value to be rejected")
+	assert.Equals(t, []string{"active", "disabled"}, ge.Attribute("Expected"))
+
+	assert.Fail(t, bind.In(map[string]interface{}{"History": []interface{}{"active", "deleted"}}, &Account{}, bind.DefaultInTool))
+}
+
+type Cents int64
+
+type Converted struct {
+	Timeout time.Duration `in:"+" out:"+"`
+	Address net.IP        `in:"+" out:"+"`
+	Price   Cents         `in:"+" out:"+"`
+	At      time.Time     `in:"+" out:"+"`
+}
+
+func TestConverters(t *testing.T) {
+	assert.Success(t, flect.RegisterConverter(Cents(0), func(value interface{}) (interface{}, error) {
+		f, err := strconv.ParseFloat(value.(string), 64)
+		return Cents(math.Round(f * 100)), err
+	}, ""))
+	defer flect.RegisterConverter(Cents(0), nil, "")
+
+	var c Converted
+	assert.Success(t, bind.In(map[string]interface{}{"Timeout": "1m30s", "Address": "10.0.0.1", "Price": "12.34", "At": "2021-06-01"}, &c, bind.DefaultInTool))
+	assert.Equals(t, 90*time.Second, c.Timeout)
+	assert.Equals(t, "10.0.0.1", c.Address.String())
+	assert.Equals(t, Cents(1234), c.Price)
+	assert.Equals(t, time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC), c.At)
+
+	out, ge := bind.Out(&c, bind.DefaultOutTool)
+	assert.Success(t, ge)
+	assert.Equals(t, "2021-06-01T00:00:00Z", out["At"])
+
+	ge = bind.In(map[string]interface{}{"Address": "not-an-ip"}, &Converted{}, bind.DefaultInTool)
+	assert.ErrorType(t, ge, &gomerr.UnmarshalError{}, "Expected an invalid address to fail")
+}
+
+type Tracked struct {
+	Name  string                 `in:"+"`
+	Count int                    `in:"+"`
+	Tags  map[string]interface{} `in:"tags"`
+}
+
+func TestNullsAndReplacements(t *testing.T) {
+	tracked := Tracked{Name: "n", Count: 1}
+	tc := bind.AddChangeTrackingToContext()
+	data := map[string]interface{}{"Name": bind.Null, "Count": nil, "tags": bind.Replacement{Value: map[string]interface{}{"a": bind.Null}}}
+	assert.Success(t, bind.In(data, &tracked, bind.DefaultInTool, tc))
+	assert.Equals(t, Tracked{Count: 1, Tags: map[string]interface{}{"a": nil}}, tracked)
+
+	set, removed := bind.TrackedChanges(tc)
+	assert.Equals(t, map[string]bool{"Name": true, "Tags": true}, set)
+	assert.Equals(t, map[string]bool{"Count": true}, removed)
+	assert.Equals(t, map[string]string{"tags": "Tags"}, bind.TrackedReplacements(tc))
+}
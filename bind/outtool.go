@@ -3,8 +3,8 @@ package bind
 import (
 	"reflect"
 	"strings"
-	"time"
 
+	"github.com/jt0/gomer/flect"
 	"github.com/jt0/gomer/gomerr"
 	"github.com/jt0/gomer/structs"
 )
@@ -16,6 +16,9 @@ func Out(v interface{}, outTool *structs.Tool, optional ...*structs.ToolContext)
 	if ge := structs.ApplyTools(v, tc, outTool); ge != nil {
 		return nil, ge
 	}
+	if ge := VerifyFieldSelection(tc); ge != nil {
+		return nil, ge
+	}
 	return tc.Get(OutKey).(map[string]interface{}), nil
 }
 
@@ -72,7 +75,7 @@ func (ap outApplierProvider) Applier(st reflect.Type, sf reflect.StructField, di
 	}
 
 	if directive == includeField || directive == "" { // b.emptyDirectiveHandling must be 'includeField' otherwise would have returned above
-		return outApplier{(*ap.toCase)(sf.Name), omitIfEmpty, ap.tool}, nil
+		return ap.outApplier(sf.Name, sf.Name, omitIfEmpty), nil
 	} else if firstChar := directive[0]; firstChar == '=' {
 		return structs.ValueApplier{directive[1:]}, nil // don't include the '='
 	} else if firstChar == '$' {
@@ -93,37 +96,88 @@ func (ap outApplierProvider) Applier(st reflect.Type, sf reflect.StructField, di
 		}
 	}
 
-	return outApplier{(*ap.toCase)(directive), omitIfEmpty, ap.tool}, nil
+	return ap.outApplier(sf.Name, directive, omitIfEmpty), nil
+}
+
+func (ap outApplierProvider) outApplier(field string, name string, omitIfEmpty bool) outApplier {
+	toName, scopedToNames := ap.casedNames(name)
+	return outApplier{field: field, toName: toName, scopedToNames: scopedToNames, omitempty: omitIfEmpty, tool: ap.tool}
 }
 
 type outApplier struct {
-	toName    string
-	omitempty bool
-	tool      *structs.Tool
+	field         string
+	toName        string
+	scopedToNames map[string]string // scope -> toName, for scopes with their own data casing
+	omitempty     bool
+	tool          *structs.Tool
 }
 
 func (a outApplier) Apply(_ reflect.Value, fv reflect.Value, tc *structs.ToolContext) gomerr.Gomerr {
-	if fv.IsZero() && a.omitempty {
+	if toName, ok := a.scopedToNames[tc.Scope()]; ok {
+		a.toName = toName
+	}
+
+	selection := currentFieldSelection(tc)
+	if a.toName != "^" { // a flattened map's entries are selected by their keys
+		if probingFields(tc) {
+			selection.matchedByField(a.toName, a.field)
+		}
+
+		var included bool
+		if included, selection = selection.selected(a.toName); !included {
+			return nil
+		}
+	}
+
+	return a.apply(fv, tc, selection)
+}
+
+// apply adds fv's output to the context's OutKey map. If selection is not nil, only the selected names of a struct's
+// fields or a map's keys are included.
+//
+// When probing the fields of a type (see VerifyFieldSelectionForType), fv is a zero value, so the (zero) element of a
+// pointer or slice is applied as if it were present. Only the selected names are probed, so a value whose output is
+// selected in full isn't, and neither is a type that's already been probed for the same selection (e.g. a recursive
+// one).
+func (a outApplier) apply(fv reflect.Value, tc *structs.ToolContext, selection *fieldSelection) gomerr.Gomerr {
+	probe := probingFields(tc)
+	if probe && !probeNeeded(fv.Type(), selection, tc) {
+		return nil
+	}
+	if fv.IsZero() && a.omitempty && !probe {
 		return nil
 	}
 
 	outData := tc.Get(OutKey).(map[string]interface{})
 
-	switch fv.Kind() {
-	case reflect.Struct:
-		// Time structs are a special case
-		if t, ok := fv.Interface().(time.Time); ok {
-			outData[a.toName] = t.Format(time.RFC3339Nano)
+	// Types with a converter to string (e.g. time.Time) are output in that form
+	if fv.Kind() != reflect.Ptr && fv.Kind() != reflect.Interface {
+		if converted, ok, ge := flect.Convert(fv.Interface(), stringType); ge != nil {
+			return ge
+		} else if ok {
+			outData[a.toName] = converted
 			return nil
 		}
+	}
 
+	switch fv.Kind() {
+	case reflect.Struct:
 		structMap := make(map[string]interface{})
 		tc.Put(OutKey, structMap)
 
+		parentSelection := tc.Get(FieldsKey)
+		if parentSelection != nil {
+			tc.Put(FieldsKey, selection)
+		}
+
 		if ge := structs.ApplyTools(fv, tc, a.tool); ge != nil {
 			return ge
 		}
 
+		if parentSelection != nil {
+			tc.Put(FieldsKey, parentSelection)
+		}
+
 		if len(structMap) > 0 || !a.omitempty {
 			outData[a.toName] = structMap
 		}
@@ -137,13 +191,17 @@ func (a outApplier) Apply(_ reflect.Value, fv reflect.Value, tc *structs.ToolCon
 			return nil
 		}
 
+		if probe {
+			return a.apply(reflect.New(fv.Type().Elem()).Elem(), tc, selection)
+		}
+
 		fvLen := fv.Len()
 		sliceOutput := make([]interface{}, 0, fvLen)
 
 		for i := 0; i < fvLen; i++ {
 			sliceMap := make(map[string]interface{}, 1)
 			tc.Put(OutKey, sliceMap)
-			if ge := a.Apply(reflect.Value{}, fv.Index(i), tc); ge != nil {
+			if ge := a.apply(fv.Index(i), tc, selection); ge != nil {
 				return ge.AddAttribute("Index", i)
 			}
 			if v, ok := sliceMap[a.toName]; ok && v != nil {
@@ -160,13 +218,23 @@ func (a outApplier) Apply(_ reflect.Value, fv reflect.Value, tc *structs.ToolCon
 		if fv.Type().Key().Kind() != reflect.String {
 			return gomerr.Configuration("Unable to produce a map without string ")
 		}
+		if probe {
+			selection.acceptAny()
+			return nil
+		}
+
 		mapOutput := make(map[string]interface{}, fv.Len())
 
 		iter := fv.MapRange()
 		for iter.Next() {
+			included, entrySelection := selection.selected(iter.Key().String())
+			if !included {
+				continue
+			}
+
 			dummyMap := make(map[string]interface{})
 			tc.Put(OutKey, dummyMap)
-			if ge := a.Apply(reflect.Value{}, iter.Value(), tc); ge != nil {
+			if ge := a.apply(iter.Value(), tc, entrySelection); ge != nil {
 				return ge.AddAttribute("Key", iter.Key().Interface())
 			}
 			if v, ok := dummyMap[a.toName]; ok && v != nil {
@@ -186,13 +254,21 @@ func (a outApplier) Apply(_ reflect.Value, fv reflect.Value, tc *structs.ToolCon
 
 		tc.Put(OutKey, outData)
 	case reflect.Ptr, reflect.Interface:
+		if probe && fv.IsNil() {
+			if fv.Kind() == reflect.Interface {
+				selection.acceptAny() // the value's type isn't known
+				return nil
+			}
+			fv = reflect.New(fv.Type().Elem())
+		}
+
 		if !fv.IsNil() {
 			elemApplier := outApplier{
 				toName:    a.toName,
 				omitempty: false, // the ptr is not empty and we don't want to potentially omit the underlying value
 				tool:      a.tool,
 			}
-			return elemApplier.Apply(reflect.Value{}, fv.Elem(), tc)
+			return elemApplier.apply(fv.Elem(), tc, selection)
 		} else if a.omitempty {
 			return nil
 		}
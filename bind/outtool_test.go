@@ -2,10 +2,12 @@ package bind_test
 
 import (
 	"encoding/json"
+	"reflect"
 	"testing"
 
 	"github.com/jt0/gomer/_test/assert"
 	"github.com/jt0/gomer/bind"
+	"github.com/jt0/gomer/gomerr"
 )
 
 type OutStruct struct {
@@ -62,3 +64,67 @@ func bindToJson(t *testing.T, stringBarOut OutStruct) []byte {
 
 	return bytes
 }
+
+type Owner struct {
+	Name  string `out:"+"`
+	Email string `out:"email"`
+}
+
+type Selected struct {
+	Id     string            `out:"id"`
+	Name   string            `out:"+"`
+	Owner  *Owner            `out:"owner"`
+	Labels map[string]string `out:"labels"`
+}
+
+func TestFieldSelection(t *testing.T) {
+	s := Selected{Id: "1", Name: "n", Owner: &Owner{Name: "o", Email: "o@example.com"}, Labels: map[string]string{"a": "1", "b": "2"}}
+
+	data, ge := bind.Out(s, bind.DefaultOutTool, bind.AddFieldSelectionToContext([]string{"id", "owner.email", "labels.b"}))
+	assert.Success(t, ge)
+	bytes, _ := json.Marshal(data)
+	assert.JsonEqual(t, []byte(`{"id": "1", "owner": {"email": "o@example.com"}, "labels": {"b": "2"}}`), bytes)
+
+	data, ge = bind.Out(s, bind.DefaultOutTool, bind.AddFieldSelectionToContext([]string{"Name", "owner"}))
+	assert.Success(t, ge)
+	bytes, _ = json.Marshal(data)
+	assert.JsonEqual(t, []byte(`{"Name": "n", "owner": {"Name": "o", "email": "o@example.com"}}`), bytes)
+
+	_, ge = bind.Out(s, bind.DefaultOutTool, bind.AddFieldSelectionToContext([]string{"id", "owner.phone", "nope"}))
+	assert.ErrorType(t, ge, &gomerr.BadValueError{}, "Expected unknown fields to fail")
+	assert.Equals(t, "nope,owner.phone", ge.(*gomerr.BadValueError).Value)
+
+	// Without a value, a nil pointer's fields are known and any map entry is accepted
+	selectedType := reflect.TypeOf(Selected{})
+	tc := bind.AddFieldSelectionToContext([]string{"id", "owner.email", "labels.any"})
+	assert.Success(t, bind.VerifyFieldSelectionForType(selectedType, bind.DefaultOutTool, tc))
+	assert.Equals(t, []string{"Id", "Labels", "Owner"}, bind.SelectedFieldNames(tc))
+
+	ge = bind.VerifyFieldSelectionForType(selectedType, bind.DefaultOutTool, bind.AddFieldSelectionToContext([]string{"owner.phone"}))
+	assert.ErrorType(t, ge, &gomerr.BadValueError{}, "Expected an unknown field to fail")
+	assert.Equals(t, "owner.phone", ge.(*gomerr.BadValueError).Value)
+}
+
+type Node struct {
+	Name     string  `out:"+"`
+	Next     *Node   `out:"+"`
+	Children []*Node `out:"+"`
+}
+
+type Loop []Loop
+
+type Looped struct {
+	Loop Loop `out:"+"`
+}
+
+func TestFieldSelectionForRecursiveType(t *testing.T) {
+	nodeType := reflect.TypeOf(Node{})
+	assert.Success(t, bind.VerifyFieldSelectionForType(nodeType, bind.DefaultOutTool, bind.AddFieldSelectionToContext([]string{"Next"})))
+	assert.Success(t, bind.VerifyFieldSelectionForType(nodeType, bind.DefaultOutTool, bind.AddFieldSelectionToContext([]string{"Next.Next.Name", "Children.Children"})))
+
+	ge := bind.VerifyFieldSelectionForType(nodeType, bind.DefaultOutTool, bind.AddFieldSelectionToContext([]string{"Children.Next.Color"}))
+	assert.ErrorType(t, ge, &gomerr.BadValueError{}, "Expected an unknown field to fail")
+	assert.Equals(t, "Children.Next.Color", ge.(*gomerr.BadValueError).Value)
+
+	assert.Success(t, bind.VerifyFieldSelectionForType(reflect.TypeOf(Looped{}), bind.DefaultOutTool, bind.AddFieldSelectionToContext([]string{"Loop.Name"})))
+}
@@ -0,0 +1,112 @@
+package bind
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/jt0/gomer/gomerr"
+	"github.com/jt0/gomer/structs"
+)
+
+const (
+	usedInputKey    = "$_gomer_bind_used_input"
+	inputPathKey    = "$_gomer_bind_input_path"
+	inputElementKey = "$_gomer_bind_input_element"
+)
+
+// VerifyInput returns an error for each of the top-level input values that wasn't bound to a field if the inTool is
+// strict for the context's scope (see StrictInput and StrictInputForScopes). It should be called after the tool has
+// been applied. Nested values are verified as the tool is applied, so the tool returns any errors for those itself.
+//
+// Each error is a gomerr.BadValueError with the UnknownValueType and is named by the value's path in the input, e.g.
+// "owner.nickname" or "items[2].color". If there's more than one error, they're combined into a gomerr.BatchError.
+func VerifyInput(inTool *structs.Tool, tc *structs.ToolContext) gomerr.Gomerr {
+	if !strictInput(inTool, tc) {
+		return nil
+	}
+
+	return withUnknownInput(nil, tc)
+}
+
+// MarkInputUsed records that the input value with the given name was bound to a field, so it isn't treated as unknown
+// in strict mode. InTool appliers do this automatically, but an extension that binds from the input data in some other
+// way can use this to do the same.
+func MarkInputUsed(name string, tc *structs.ToolContext) {
+	used, _ := tc.Get(usedInputKey).(map[string]bool)
+	if used == nil {
+		used = make(map[string]bool)
+		tc.Put(usedInputKey, used)
+	}
+	used[name] = true
+}
+
+func strictInput(inTool *structs.Tool, tc *structs.ToolContext) bool {
+	return inConfiguration(inTool).strictFor(tc.Scope())
+}
+
+// descendInput starts tracking the used input values of the named (nested) value, and returns a function that restores
+// the tracking of the current one.
+func descendInput(name string, tc *structs.ToolContext) func() {
+	used, path, element := tc.Get(usedInputKey), tc.Get(inputPathKey), tc.Get(inputElementKey)
+	pathString, _ := path.(string)
+	elementString, _ := element.(string)
+
+	tc.Put(usedInputKey, make(map[string]bool)).Put(inputPathKey, pathString+name+elementString+".").Put(inputElementKey, "")
+
+	return func() {
+		tc.Put(usedInputKey, used).Put(inputPathKey, path).Put(inputElementKey, element)
+	}
+}
+
+// inputElement appends the index or key of a slice or map element to the name of the input value being bound, and
+// returns a function that restores the current one.
+func inputElement(indexOrKey interface{}, tc *structs.ToolContext) func() {
+	element := tc.Get(inputElementKey)
+	elementString, _ := element.(string)
+
+	switch ik := indexOrKey.(type) {
+	case int:
+		elementString += "[" + strconv.Itoa(ik) + "]"
+	case string:
+		elementString += "[" + ik + "]"
+	}
+	tc.Put(inputElementKey, elementString)
+
+	return func() {
+		tc.Put(inputElementKey, element)
+	}
+}
+
+// withUnknownInput adds an error for each unused value in the current input data to those in ge (if any).
+func withUnknownInput(ge gomerr.Gomerr, tc *structs.ToolContext) gomerr.Gomerr {
+	inData, ok := tc.Get(InKey).(map[string]interface{})
+	if !ok {
+		return ge
+	}
+
+	used, _ := tc.Get(usedInputKey).(map[string]bool)
+	var unknown []string
+	for name := range inData {
+		if !used[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) == 0 {
+		return ge
+	}
+	sort.Strings(unknown)
+
+	var errors []gomerr.Gomerr
+	if be, isBatch := ge.(*gomerr.BatchError); isBatch {
+		errors = append(errors, be.Errors()...)
+	} else if ge != nil {
+		errors = append(errors, ge)
+	}
+
+	path, _ := tc.Get(inputPathKey).(string)
+	for _, name := range unknown {
+		errors = append(errors, gomerr.UnknownValue(path+name, inData[name]).WithReason("Unknown field"))
+	}
+
+	return gomerr.Batcher(errors)
+}
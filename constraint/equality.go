@@ -47,3 +47,31 @@ func OneOf(values ...interface{}) Constraint {
 		return NotSatisfied(toTest)
 	})
 }
+
+// Enum tests that a value of the enum type (see flect.Enum) is either empty or one of the type's values. The
+// validation tool applies it to enum fields automatically, so it rarely needs to be used directly.
+func Enum(enumType reflect.Type) Constraint {
+	values, ok := flect.EnumValues(enumType)
+	if !ok {
+		panic(gomerr.Configuration("Enum constraint defined for a non-enum type: " + enumType.String()))
+	}
+
+	return New("Enum", values, func(toTest interface{}) gomerr.Gomerr {
+		ttv, ok := flect.ReadableIndirectValue(toTest)
+		if !ok {
+			return nil // a nil pointer is treated as empty
+		} else if ttv.Kind() != reflect.String {
+			return NotSatisfied(toTest)
+		}
+
+		if tts := ttv.String(); tts != "" {
+			for _, value := range values {
+				if tts == value {
+					return nil
+				}
+			}
+			return NotSatisfied(toTest)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,39 @@
+package constraint_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jt0/gomer/_test/assert"
+	"github.com/jt0/gomer/constraint"
+	"github.com/jt0/gomer/flect"
+)
+
+type Color string
+
+func (Color) Values() []string {
+	return []string{"red", "green", "blue"}
+}
+
+type Size string
+
+type Shirt struct {
+	Color Color `validate:"required"`
+	Size  *Size
+}
+
+func TestEnum(t *testing.T) {
+	assert.Success(t, flect.RegisterEnum(Size(""), "S", "M", "L"))
+
+	c := constraint.Enum(reflect.TypeOf(Color("")))
+	assert.Success(t, c.Validate("field", Color("red")))
+	assert.Success(t, c.Validate("field", Color("")))
+	assert.Fail(t, c.Validate("field", Color("purple")))
+	assert.Equals(t, []string{"red", "green", "blue"}, c.Parameters())
+
+	small, huge := Size("S"), Size("XXL")
+	assert.Success(t, constraint.Validate(&Shirt{Color: "blue", Size: &small}, constraint.DefaultValidationTool))
+	assert.Fail(t, constraint.Validate(&Shirt{Color: "purple"}, constraint.DefaultValidationTool))
+	assert.Fail(t, constraint.Validate(&Shirt{Color: "blue", Size: &huge}, constraint.DefaultValidationTool))
+	assert.Fail(t, constraint.Validate(&Shirt{}, constraint.DefaultValidationTool)) // still required
+}
@@ -34,13 +34,25 @@ type validationApplierProvider struct {
 }
 
 func (ap validationApplierProvider) Applier(sv reflect.Type, sf reflect.StructField, directive string, _ string) (structs.Applier, gomerr.Gomerr) {
-	if directive == "" {
-		return nil, nil
+	// Enum fields are always limited to their type's values, in addition to any other constraints
+	var enum Constraint
+	if _, isEnum := flect.EnumValues(sf.Type); isEnum {
+		enum = Enum(sf.Type)
 	}
 
-	c, ge := constraintFor(directive, none, sf)
-	if ge != nil {
-		return nil, gomerr.Configuration("Cannot process directive").Wrap(ge).AddAttribute("Directive", directive)
+	var c Constraint
+	if directive != "" {
+		var ge gomerr.Gomerr
+		if c, ge = constraintFor(directive, none, sf); ge != nil {
+			return nil, gomerr.Configuration("Cannot process directive").Wrap(ge).AddAttribute("Directive", directive)
+		}
+		if enum != nil {
+			c = And(enum, c)
+		}
+	} else if enum != nil {
+		c = enum
+	} else {
+		return nil, nil
 	}
 
 	var target string
@@ -0,0 +1,17 @@
+package dataerr
+
+import (
+	"github.com/jt0/gomer/gomerr"
+)
+
+// VersionMismatchError indicates that a data.Versioned persistable wasn't written because its stored version is no
+// longer the one that was read (i.e. it's been changed in the meantime).
+type VersionMismatchError struct {
+	gomerr.Gomerr
+	TypeName string
+	Version  int64
+}
+
+func VersionMismatch(typeName string, version int64) *VersionMismatchError {
+	return gomerr.Build(new(VersionMismatchError), typeName, version).(*VersionMismatchError)
+}
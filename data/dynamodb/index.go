@@ -179,7 +179,7 @@ func (i *index) candidate(qv reflect.Value, ptName string) *candidate {
 		}
 
 		if i.queryWildcardChar != 0 {
-			if s, ok := fv.Interface().(string); ok && s != "" && s[len(s)-1] == i.queryWildcardChar {
+			if s := fv.String(); fv.Kind() == reflect.String && s != "" && s[len(s)-1] == i.queryWildcardChar {
 				return nil
 			}
 		}
@@ -308,6 +308,9 @@ func fieldValue(fieldName string, sv reflect.Value) string {
 			if v.Kind() == reflect.Ptr && !v.IsNil() {
 				v = v.Elem()
 			}
+			if v.Kind() == reflect.String {
+				return v.String() // the underlying value of string types (e.g. enums), even if they implement fmt.Stringer
+			}
 			return fmt.Sprint(v.Interface())
 		} else {
 			return ""
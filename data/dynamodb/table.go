@@ -218,7 +218,12 @@ func (t *table) Create(p data.Persistable) (ge gomerr.Gomerr) {
 		}
 	}()
 
-	ge = t.put(p, t.persistableTypes[p.TypeName()].fieldConstraints, true)
+	// The version is only changed if the persistable is stored
+	_, version, _ := data.Version(p)
+	data.SetVersion(p, 1)
+	if ge = t.put(p, t.persistableTypes[p.TypeName()].fieldConstraints, true, nil); ge != nil {
+		data.SetVersion(p, version)
+	}
 
 	return
 }
@@ -232,43 +237,59 @@ func (t *table) Update(p data.Persistable, update data.Persistable) (ge gomerr.G
 
 	// TODO:p1 support partial update vs put()
 
+	// The version that was read is the one that's expected to still be stored
+	var expectedVersion *int64
+	if _, version, versioned := data.Version(p); versioned {
+		expectedVersion = &version
+	}
+
 	fieldConstraintsToCheck := make(map[string]constraint.Constraint)
 	if update != nil {
 		uv := reflect.ValueOf(update).Elem()
 		pv := reflect.ValueOf(p).Elem()
 
-		for i := 0; i < uv.NumField(); i++ {
-			uField := uv.Field(i)
-			// TODO:p0 Support structs. Will want to recurse through and not bother w/ CanSet() checks until we know
-			//         we're dealing w/ a scalar.
-			if !uField.CanSet() || uField.Kind() == reflect.Struct || (uField.Kind() == reflect.Ptr && uField.Elem().Kind() == reflect.Struct) {
-				continue
-			}
+		// An explicit update identifies the fields it changes, so (unlike below) zero values and structs are stored too
+		var changes *data.Changes
+		if explicitUpdate, ok := update.(data.ExplicitUpdate); ok {
+			changes = explicitUpdate.UpdateChanges()
+		}
 
-			pField := pv.Field(i)
-			if reflect.DeepEqual(uField.Interface(), pField.Interface()) {
-				uField.Set(reflect.Zero(uField.Type()))
-			} else if uField.Kind() == reflect.Ptr {
-				if uField.IsNil() {
+		if changes != nil {
+			applyChanges(pv, uv, changes)
+		} else {
+			for i := 0; i < uv.NumField(); i++ {
+				uField := uv.Field(i)
+				// TODO:p0 Support structs. Will want to recurse through and not bother w/ CanSet() checks until we know
+				//         we're dealing w/ a scalar.
+				if !uField.CanSet() || uField.Kind() == reflect.Struct || (uField.Kind() == reflect.Ptr && uField.Elem().Kind() == reflect.Struct) {
 					continue
 				}
-				if !pField.IsNil() && reflect.DeepEqual(uField.Elem().Interface(), pField.Elem().Interface()) {
+
+				pField := pv.Field(i)
+				if reflect.DeepEqual(uField.Interface(), pField.Interface()) {
 					uField.Set(reflect.Zero(uField.Type()))
+				} else if uField.Kind() == reflect.Ptr {
+					if uField.IsNil() {
+						continue
+					}
+					if !pField.IsNil() && reflect.DeepEqual(uField.Elem().Interface(), pField.Elem().Interface()) {
+						uField.Set(reflect.Zero(uField.Type()))
+					} else {
+						pField.Set(uField)
+					}
 				} else {
+					if uField.IsZero() {
+						continue
+					}
 					pField.Set(uField)
 				}
-			} else {
-				if uField.IsZero() {
-					continue
-				}
-				pField.Set(uField)
 			}
 		}
 
 	nextCondition:
 		for fieldName, fieldConstraint := range t.persistableTypes[p.TypeName()].fieldConstraints {
 			// Test if the field with the constraint has been updated. If so, add the constraint and continue.
-			if !uv.FieldByName(fieldName).IsZero() {
+			if changes != nil && changes.Changed(fieldName) || changes == nil && !uv.FieldByName(fieldName).IsZero() {
 				fieldConstraintsToCheck[fieldName] = fieldConstraint
 				continue nextCondition
 			}
@@ -276,8 +297,12 @@ func (t *table) Update(p data.Persistable, update data.Persistable) (ge gomerr.G
 			// See if any of the other fields that are used to determine uniqueness have been updated. If yes, add the
 			// condition to the list and continue to the next condition.
 			for _, otherField := range fieldConstraint.Parameters().([]string) {
-				uField := uv.FieldByName(otherField)
-				if !uField.IsZero() /* TODO: remove rest once structs supported above */ && uField.Interface() != pv.FieldByName(otherField).Interface() {
+				if changes != nil {
+					if changes.Changed(otherField) {
+						fieldConstraintsToCheck[fieldName] = fieldConstraint
+						continue nextCondition
+					}
+				} else if uField := uv.FieldByName(otherField); !uField.IsZero() /* TODO: remove rest once structs supported above */ && uField.Interface() != pv.FieldByName(otherField).Interface() {
 					fieldConstraintsToCheck[fieldName] = fieldConstraint
 					continue nextCondition
 				}
@@ -286,12 +311,38 @@ func (t *table) Update(p data.Persistable, update data.Persistable) (ge gomerr.G
 		}
 	}
 
-	ge = t.put(p, fieldConstraintsToCheck, false)
+	if expectedVersion == nil {
+		return t.put(p, fieldConstraintsToCheck, false, nil)
+	}
+
+	// The version is only changed if the update is stored, so a failed one (e.g. due to a version mismatch) can be retried
+	data.SetVersion(p, *expectedVersion+1)
+	if ge = t.put(p, fieldConstraintsToCheck, false, expectedVersion); ge != nil {
+		data.SetVersion(p, *expectedVersion)
+	}
 
 	return
 }
 
-func (t *table) put(p data.Persistable, fieldConstraints map[string]constraint.Constraint, ensureUniqueId bool) gomerr.Gomerr {
+// applyChanges sets each of the fields set by an explicit update to the update's value, and clears each of the ones it
+// removes.
+func applyChanges(pv, uv reflect.Value, changes *data.Changes) {
+	for fieldName := range changes.Set {
+		if pField := pv.FieldByName(fieldName); pField.CanSet() {
+			pField.Set(uv.FieldByName(fieldName))
+		}
+	}
+
+	for fieldName := range changes.Removed {
+		if pField := pv.FieldByName(fieldName); pField.CanSet() {
+			pField.Set(reflect.Zero(pField.Type()))
+		}
+	}
+}
+
+// put stores the persistable. If expectedVersion isn't nil, the persistable must be data.Versioned and the put only
+// succeeds if the stored version matches it.
+func (t *table) put(p data.Persistable, fieldConstraints map[string]constraint.Constraint, ensureUniqueId bool, expectedVersion *int64) gomerr.Gomerr {
 	for fieldName, fieldConstraint := range fieldConstraints {
 		if ge := fieldConstraint.Validate(fieldName, p); ge != nil {
 			return ge
@@ -311,29 +362,33 @@ func (t *table) put(p data.Persistable, fieldConstraints map[string]constraint.C
 
 	// TODO: here we could compare the current av map w/ one we stashed into the object somewhere
 
-	var uniqueIdConditionExpression *string
+	var conditionExpression *string
 	if ensureUniqueId {
 		expression := fmt.Sprintf("attribute_not_exists(%s)", t.pk.name)
 		if t.sk != nil {
 			expression += fmt.Sprintf(" AND attribute_not_exists(%s)", t.sk.name)
 		}
-		uniqueIdConditionExpression = &expression
+		conditionExpression = &expression
 	}
 
-	// TODO:p1 optimistic locking
-
 	input := &dynamodb.PutItemInput{
 		Item:                av,
 		TableName:           t.tableName,
-		ConditionExpression: uniqueIdConditionExpression,
+		ConditionExpression: conditionExpression,
+	}
+	if expectedVersion != nil { // a put with an expected version never has to ensure a unique id
+		expression, names, values := t.versionCondition(p, *expectedVersion)
+		input.ConditionExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues = &expression, names, values
 	}
 	_, err = t.ddb.PutItem(input) // TODO:p3 look at result data to track capacity or other info?
 	if err != nil {
 		if awsErr, ok := err.(awserr.Error); ok {
 			switch awsErr.Code() {
 			case dynamodb.ErrCodeConditionalCheckFailedException:
-				if ensureUniqueId {
-					return gomerr.Internal("Unique id check failed, retry with a new id value").Wrap(err)
+				if expectedVersion != nil {
+					return dataerr.VersionMismatch(p.TypeName(), *expectedVersion).Wrap(err)
+				} else if ensureUniqueId {
+					return gomerr.Conflict(p, "An item with the same id already exists").Wrap(err)
 				} else {
 					return gomerr.Dependency("DynamoDB", input).Wrap(err)
 				}
@@ -350,7 +405,17 @@ func (t *table) put(p data.Persistable, fieldConstraints map[string]constraint.C
 	return nil
 }
 
-func (t *table) Read(p data.Persistable) (ge gomerr.Gomerr) {
+// Read reads p's item. If p is a data.AttributeSelector, only the selected attributes are read.
+func (t *table) Read(p data.Persistable) gomerr.Gomerr {
+	return t.read(p, true)
+}
+
+// ReadFull reads all of p's item's attributes (see data.FullReader).
+func (t *table) ReadFull(p data.Persistable) gomerr.Gomerr {
+	return t.read(p, false)
+}
+
+func (t *table) read(p data.Persistable, project bool) (ge gomerr.Gomerr) {
 	defer func() {
 		if ge != nil {
 			ge = dataerr.Store("Read", p).Wrap(ge)
@@ -363,11 +428,18 @@ func (t *table) Read(p data.Persistable) (ge gomerr.Gomerr) {
 		return ge
 	}
 
+	expressionAttributeNames := make(map[string]*string)
 	input := &dynamodb.GetItemInput{
 		Key:            key,
 		ConsistentRead: consistentRead(t.consistencyType(p), true),
 		TableName:      t.tableName,
 	}
+	if project {
+		input.ProjectionExpression = t.projectionExpression(p, p.TypeName(), nil, expressionAttributeNames)
+	}
+	if len(expressionAttributeNames) > 0 {
+		input.ExpressionAttributeNames = expressionAttributeNames
+	}
 	output, err := t.ddb.GetItem(input)
 	if err != nil {
 		if awsErr, ok := err.(awserr.Error); ok {
@@ -423,11 +495,28 @@ func (t *table) Delete(p data.Persistable) (ge gomerr.Gomerr) {
 		TableName:           t.tableName,
 		ConditionExpression: existenceCheckExpression,
 	}
+
+	// A persistable with a version has been read, so it's only deleted if it hasn't been changed since
+	_, version, versioned := data.Version(p)
+	versioned = versioned && version != 0
+	if versioned {
+		expression, names, values := t.versionCondition(p, version)
+		if existenceCheckExpression != nil {
+			expression = *existenceCheckExpression + " AND " + expression
+		}
+		input.ConditionExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues = &expression, names, values
+	}
+
 	_, err := t.ddb.DeleteItem(input)
 	if err != nil {
 		if awsErr, ok := err.(awserr.Error); ok {
 			switch awsErr.Code() {
-			case dynamodb.ErrCodeResourceNotFoundException, dynamodb.ErrCodeConditionalCheckFailedException:
+			case dynamodb.ErrCodeConditionalCheckFailedException:
+				if versioned {
+					return dataerr.VersionMismatch(p.TypeName(), version).Wrap(err)
+				}
+				return dataerr.PersistableNotFound(p.TypeName(), key).Wrap(err)
+			case dynamodb.ErrCodeResourceNotFoundException:
 				return dataerr.PersistableNotFound(p.TypeName(), key).Wrap(err)
 			case dynamodb.ErrCodeRequestLimitExceeded, dynamodb.ErrCodeProvisionedThroughputExceededException:
 				return limit.UnquantifiedExcess("DynamoDB", "throughput").Wrap(awsErr)
@@ -440,6 +529,79 @@ func (t *table) Delete(p data.Persistable) (ge gomerr.Gomerr) {
 	return nil
 }
 
+// versionCondition returns a condition expression (with its attribute names and values) that checks the stored
+// version of the data.Versioned persistable is the expected one. A persistable stored before it was versioned has no
+// version attribute, which is treated as a version of 0.
+func (t *table) versionCondition(p data.Persistable, expectedVersion int64) (string, map[string]*string, map[string]*dynamodb.AttributeValue) {
+	field, _, _ := data.Version(p)
+	attributeName := field
+	if dbName, ok := t.persistableTypes[p.TypeName()].dbNames[field]; ok {
+		attributeName = dbName
+	}
+
+	expression := "#version = :version"
+	if expectedVersion == 0 {
+		expression = "(attribute_not_exists(#version) OR #version = :version)"
+	}
+	versionString := strconv.FormatInt(expectedVersion, 10)
+
+	return expression, map[string]*string{"#version": &attributeName}, map[string]*dynamodb.AttributeValue{":version": {N: &versionString}}
+}
+
+const (
+	maxBatchWriteItems   = 25
+	maxBatchWriteRetries = 3
+)
+
+// BatchDelete removes the provided persistables using as few BatchWriteItem calls as possible. Unlike Delete, there
+// is no existence check for each item, so the FailDeleteIfNotPresent configuration value is ignored.
+func (t *table) BatchDelete(ps []data.Persistable) (ge gomerr.Gomerr) {
+	defer func() {
+		if ge != nil {
+			ge = dataerr.Store("BatchDelete", ps).Wrap(ge)
+		}
+	}()
+
+	writeRequests := make([]*dynamodb.WriteRequest, 0, len(ps))
+	for _, p := range ps {
+		key := make(map[string]*dynamodb.AttributeValue, 2)
+		if ge = t.populateKeyValues(key, p, t.valueSeparatorChar, true); ge != nil {
+			return ge
+		}
+		writeRequests = append(writeRequests, &dynamodb.WriteRequest{DeleteRequest: &dynamodb.DeleteRequest{Key: key}})
+	}
+
+	for start := 0; start < len(writeRequests); start += maxBatchWriteItems {
+		end := start + maxBatchWriteItems
+		if end > len(writeRequests) {
+			end = len(writeRequests)
+		}
+
+		input := &dynamodb.BatchWriteItemInput{RequestItems: map[string][]*dynamodb.WriteRequest{*t.tableName: writeRequests[start:end]}}
+		for attempt := 0; len(input.RequestItems) > 0; attempt++ {
+			if attempt > maxBatchWriteRetries {
+				return gomerr.Dependency("DynamoDB", input).AddAttribute("Reason", "Unprocessed items remain after retries")
+			}
+
+			output, err := t.ddb.BatchWriteItem(input)
+			if err != nil {
+				if awsErr, ok := err.(awserr.Error); ok {
+					switch awsErr.Code() {
+					case dynamodb.ErrCodeRequestLimitExceeded, dynamodb.ErrCodeProvisionedThroughputExceededException:
+						return limit.UnquantifiedExcess("DynamoDB", "throughput").Wrap(awsErr)
+					}
+				}
+
+				return gomerr.Dependency("DynamoDB", input).Wrap(err)
+			}
+
+			input.RequestItems = output.UnprocessedItems
+		}
+	}
+
+	return nil
+}
+
 func (t *table) Query(q data.Queryable) (ge gomerr.Gomerr) {
 	defer func() {
 		if ge != nil {
@@ -567,21 +729,12 @@ func (t *table) buildQueryInput(q data.Queryable, persistableTypeName string) (*
 		filterExpression = &fe
 	}
 
-	// for _, attribute := range q.ResponseFields() {
-	// 	safeName(attribute, expressionAttributeNames)
-	// }
+	projectionExpression := t.projectionExpression(q, persistableTypeName, idx, expressionAttributeNames)
 
 	if len(expressionAttributeNames) == 0 {
 		expressionAttributeNames = nil
 	}
 
-	// TODO:p2 projectionExpression
-	// var projectionExpressionPtr *string
-	// projectionExpression := strings.Join(attributes, ",") // Join() returns "" if len(attributes) == 0
-	// if projectionExpression != "" {
-	// 	projectionExpressionPtr = &projectionExpression
-	// }
-
 	exclusiveStartKey, ge := t.nextTokenizer.untokenize(q)
 	if ge != nil {
 		return nil, ge
@@ -597,8 +750,8 @@ func (t *table) buildQueryInput(q data.Queryable, persistableTypeName string) (*
 		FilterExpression:          filterExpression,
 		ExclusiveStartKey:         exclusiveStartKey,
 		Limit:                     t.limit(q.MaximumPageSize()),
-		// ProjectionExpression:      projectionExpressionPtr,
-		ScanIndexForward: &ascending,
+		ProjectionExpression:      projectionExpression,
+		ScanIndexForward:          &ascending,
 	}
 
 	return input, nil
@@ -633,7 +786,12 @@ func (t *table) filterExpression(q data.Queryable, idx *index, persistableTypeNa
 		if qfv.Kind() == reflect.Struct {
 			continue
 		}
-		s := fmt.Sprint(qfv.Interface())
+		var s string
+		if qfv.Kind() == reflect.String {
+			s = qfv.String() // the underlying value of string types (e.g. enums), even if they implement fmt.Stringer
+		} else {
+			s = fmt.Sprint(qfv.Interface())
+		}
 		if len(s) == 0 {
 			continue
 		}
@@ -653,6 +811,56 @@ func (t *table) filterExpression(q data.Queryable, idx *index, persistableTypeNa
 	return exp, nil
 }
 
+// projectionExpression returns the expression that limits the attributes read to the ones selected (see
+// data.AttributeSelector), or nil if all should be read. A data.Versioned persistable's version field is always
+// included, and for queries, so are the fields used in idx's keys so that the returned items can be identified.
+func (t *table) projectionExpression(v interface{}, persistableTypeName string, idx *index, expressionAttributeNames map[string]*string) *string {
+	selector, ok := v.(data.AttributeSelector)
+	if !ok {
+		return nil
+	}
+
+	selected := selector.SelectedAttributes()
+	if len(selected) == 0 {
+		return nil
+	}
+
+	fields := append([]string{}, selected...)
+	if versioned, ok := v.(data.Versioned); ok {
+		fields = append(fields, versioned.VersionField()) // the version identifies what was read (e.g. for an ETag)
+	}
+	if idx != nil {
+		for _, ka := range idx.keyAttributes() {
+			for _, kf := range ka.keyFieldsByPersistable[persistableTypeName] {
+				if kf.name[:1] != "'" { // skip static values
+					fields = append(fields, kf.name)
+				}
+			}
+		}
+	}
+
+	pt := t.persistableTypes[persistableTypeName]
+	included := make(map[string]bool, len(fields))
+	names := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if included[field] {
+			continue
+		}
+		included[field] = true
+
+		attributeName := field
+		if pt != nil {
+			if dbName, ok := pt.dbNames[field]; ok {
+				attributeName = dbName
+			}
+		}
+		names = append(names, safeName(attributeName, expressionAttributeNames))
+	}
+
+	projectionExpression := strings.Join(names, ",")
+	return &projectionExpression
+}
+
 func (t *table) runQuery(input *dynamodb.QueryInput) (*dynamodb.QueryOutput, gomerr.Gomerr) {
 	output, err := t.ddb.Query(input)
 	if err != nil {
@@ -12,6 +12,70 @@ type Store interface {
 	Query(q Queryable) gomerr.Gomerr
 }
 
+// BatchDeleter is an optional interface a Store can implement if it is able to delete more than one Persistable with
+// a single request to the underlying data source.
+type BatchDeleter interface {
+	BatchDelete(ps []Persistable) gomerr.Gomerr
+}
+
+// AttributeSelector is an optional interface a Persistable or Queryable can implement to limit the attributes a Store
+// reads (e.g. to the fields a client asked to be returned). The names are of the Persistable's fields, and a nil or
+// empty result reads all attributes. Stores that can't limit what they read ignore it, and those that can must also
+// implement FullReader.
+type AttributeSelector interface {
+	SelectedAttributes() []string
+}
+
+// FullReader is an optional interface a Store implements if its Read limits the attributes read to those selected by
+// an AttributeSelector. ReadFull reads all of them regardless.
+type FullReader interface {
+	ReadFull(p Persistable) gomerr.Gomerr
+}
+
+// ReadFull reads all of p's attributes from the store, even if p is an AttributeSelector. A read whose result is
+// written back (e.g. before an update) must use it so that the attributes that weren't selected aren't lost.
+func ReadFull(s Store, p Persistable) gomerr.Gomerr {
+	if fullReader, ok := s.(FullReader); ok {
+		return fullReader.ReadFull(p)
+	}
+	return s.Read(p)
+}
+
+// ExplicitUpdate is an optional interface the update passed to Store.Update can implement to identify exactly which
+// fields it changes (e.g. when it was bound from a JSON merge patch). If UpdateChanges returns nil, a Store treats the
+// update's zero values as unchanged.
+type ExplicitUpdate interface {
+	UpdateChanges() *Changes
+}
+
+// Changes lists the fields changed by an explicit update. Names are those of the Persistable's fields. Each field in
+// Set is stored with the update's value, even if it's a zero value (so a field can be set to false or 0), each one in
+// Removed is cleared, and every other field is left unchanged.
+type Changes struct {
+	Set     map[string]bool
+	Removed map[string]bool
+}
+
+// Changed returns true if the field is either set or removed.
+func (c *Changes) Changed(field string) bool {
+	return c.Set[field] || c.Removed[field]
+}
+
+// Discard removes the field from the changes so it's left unchanged.
+func (c *Changes) Discard(field string) {
+	delete(c.Set, field)
+	delete(c.Removed, field)
+}
+
+// Versioned is an optional interface a Persistable can implement to protect it from lost updates. VersionField names
+// the integer field that holds its version. A Store that supports conditional writes sets the version when the
+// Persistable is created and increments it each time it's updated. An Update (or a Delete of a Persistable with a
+// non-zero version) fails with a dataerr.VersionMismatchError if the stored version is no longer the one that was
+// read.
+type Versioned interface {
+	VersionField() string
+}
+
 type Persistable interface {
 	TypeName() string
 	NewQueryable() Queryable
@@ -0,0 +1,38 @@
+package data
+
+import (
+	"reflect"
+)
+
+// Version returns the name and value of the Persistable's version field (see Versioned). The result is false if the
+// Persistable isn't Versioned or the field isn't an integer.
+func Version(p Persistable) (string, int64, bool) {
+	versioned, ok := p.(Versioned)
+	if !ok {
+		return "", 0, false
+	}
+
+	field := versioned.VersionField()
+	switch fv := reflect.ValueOf(p).Elem().FieldByName(field); fv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return field, fv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return field, int64(fv.Uint()), true
+	}
+
+	return "", 0, false
+}
+
+// SetVersion sets the value of the Persistable's version field. It does nothing if Version would return false.
+func SetVersion(p Persistable, version int64) {
+	field, _, ok := Version(p)
+	if !ok {
+		return
+	}
+
+	if fv := reflect.ValueOf(p).Elem().FieldByName(field); fv.CanInt() {
+		fv.SetInt(version)
+	} else {
+		fv.SetUint(uint64(version))
+	}
+}
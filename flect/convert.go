@@ -0,0 +1,164 @@
+package flect
+
+import (
+	"encoding"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/jt0/gomer/gomerr"
+)
+
+// Converter converts a value to the type it's registered for (see RegisterConverter). It may also return a pointer to
+// the type or a value convertible to it.
+type Converter func(value interface{}) (interface{}, error)
+
+type converterKey struct {
+	source reflect.Type // nil for any source type
+	target reflect.Type
+}
+
+var (
+	converters = make(map[converterKey]Converter)
+
+	stringType          = reflect.TypeOf("")
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func init() {
+	_ = RegisterConverter(time.Time{}, TimeConverter(time.RFC3339Nano, "2006-01-02"), "")
+	_ = RegisterConverter("", func(value interface{}) (interface{}, error) {
+		return value.(time.Time).Format(time.RFC3339Nano), nil
+	}, time.Time{})
+	_ = RegisterConverter(time.Duration(0), func(value interface{}) (interface{}, error) {
+		if d, err := time.ParseDuration(value.(string)); err == nil {
+			return d, nil
+		} else if n, nErr := strconv.ParseInt(value.(string), 0, 64); nErr == nil {
+			return time.Duration(n), nil // a number of nanoseconds
+		} else {
+			return nil, err
+		}
+	}, "")
+}
+
+// RegisterConverter sets the converter used by SetValue (and so the in tools, request binding, and constraint
+// parameters) to produce values of the target type. If any sources are provided, the converter is only used for
+// values of those types. Otherwise, it's used for values of any type other than the target's. Both the target and
+// sources can be provided as a value of the type or as its reflect.Type, and a nil converter removes one that was
+// previously registered.
+//
+// A converter to a string from a source type (e.g. time.Time) is also used when producing output values, such as by
+// an out tool or when setting a response header.
+//
+// Built-in converters parse time.Time values from RFC3339 strings (or just a date) and format them as RFC3339 strings,
+// and parse time.Duration values from strings such as "1m30s". Other types that implement encoding.TextUnmarshaler
+// (e.g. net.IP and many UUID and decimal types) are converted from strings without needing to be registered.
+func RegisterConverter(target interface{}, converter Converter, sources ...interface{}) gomerr.Gomerr {
+	if target == nil {
+		return gomerr.Configuration("Converter registered without a target type")
+	}
+	tt := IndirectType(target)
+
+	keys := []converterKey{{target: tt}}
+	if len(sources) > 0 {
+		keys = keys[:0]
+		for _, source := range sources {
+			if source == nil {
+				return gomerr.Configuration("Converter registered with a nil source type for: " + tt.String())
+			}
+			keys = append(keys, converterKey{source: IndirectType(source), target: tt})
+		}
+	}
+
+	for _, key := range keys {
+		if converter == nil {
+			delete(converters, key)
+		} else {
+			converters[key] = converter
+		}
+	}
+
+	return nil
+}
+
+// TimeConverter returns a Converter that parses strings as time.Time values using the first of the layouts that
+// matches, e.g.:
+//
+//	flect.RegisterConverter(time.Time{}, flect.TimeConverter(time.RFC1123, time.RFC3339), "")
+func TimeConverter(layouts ...string) Converter {
+	return func(value interface{}) (interface{}, error) {
+		var err error
+		for _, layout := range layouts {
+			var t time.Time
+			if t, err = time.Parse(layout, value.(string)); err == nil {
+				return t, nil
+			}
+		}
+		return nil, err
+	}
+}
+
+// Convert converts the value to the targetType (or the type it points to) using a registered converter or, for a
+// string or []byte value, the type's encoding.TextUnmarshaler implementation. It returns false if neither applies.
+func Convert(value interface{}, targetType reflect.Type) (interface{}, bool, gomerr.Gomerr) {
+	vv, ok := ReadableIndirectValue(value)
+	if !ok || !vv.CanInterface() {
+		return nil, false, nil
+	}
+	value = vv.Interface()
+
+	if targetType.Kind() == reflect.Ptr {
+		targetType = targetType.Elem()
+	}
+	if vv.Type() == targetType {
+		return nil, false, nil
+	}
+
+	converter, ok := converters[converterKey{source: vv.Type(), target: targetType}]
+	if !ok {
+		converter, ok = converters[converterKey{target: targetType}]
+	}
+	if ok {
+		converted, err := converter(value)
+		if err != nil {
+			return nil, true, gomerr.Unmarshal("value", value, targetType.String()).Wrap(err)
+		}
+		return converted, true, nil
+	}
+
+	var text []byte
+	switch tv := value.(type) {
+	case string:
+		text = []byte(tv)
+	case []byte:
+		text = tv
+	default:
+		return nil, false, nil
+	}
+
+	if !reflect.PtrTo(targetType).Implements(textUnmarshalerType) {
+		return nil, false, nil
+	}
+
+	converted := reflect.New(targetType)
+	if err := converted.Interface().(encoding.TextUnmarshaler).UnmarshalText(text); err != nil {
+		return nil, true, gomerr.Unmarshal("value", value, targetType.String()).Wrap(err)
+	}
+
+	return converted.Elem().Interface(), true, nil
+}
+
+// ConvertsString returns true if Convert converts strings to the targetType (or the type it points to).
+func ConvertsString(targetType reflect.Type) bool {
+	if targetType.Kind() == reflect.Ptr {
+		targetType = targetType.Elem()
+	}
+
+	if _, ok := converters[converterKey{source: stringType, target: targetType}]; ok {
+		return true
+	} else if _, ok = converters[converterKey{target: targetType}]; ok {
+		return true
+	}
+
+	return reflect.PtrTo(targetType).Implements(textUnmarshalerType)
+}
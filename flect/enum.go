@@ -0,0 +1,76 @@
+package flect
+
+import (
+	"reflect"
+
+	"github.com/jt0/gomer/gomerr"
+)
+
+// Enum is implemented by string types with a fixed set of values, e.g.:
+//
+//	type Color string
+//
+//	func (Color) Values() []string { return []string{"red", "green", "blue"} }
+//
+// Strings set to an Enum (or pointer to one) with SetValue must be one of its values, and the constraint and
+// data packages use the values to validate and persist the type.
+type Enum interface {
+	Values() []string
+}
+
+var (
+	enumType   = reflect.TypeOf((*Enum)(nil)).Elem()
+	enumValues = make(map[reflect.Type][]string)
+)
+
+// RegisterEnum declares the values of a string type that doesn't implement Enum (e.g. one defined in another package).
+// The enum can be a value of the type or its reflect.Type. Registered values take precedence over those returned by
+// an Enum implementation.
+func RegisterEnum(enum interface{}, values ...string) gomerr.Gomerr {
+	et := IndirectType(enum)
+	if et.Kind() != reflect.String {
+		return gomerr.Configuration("Enum types must have a string kind, not: " + et.String())
+	} else if len(values) == 0 {
+		return gomerr.Configuration("Enum type registered without values: " + et.String())
+	}
+
+	enumValues[et] = values
+	return nil
+}
+
+// EnumValues returns the values of t (or the type it points to) if it's a registered enum type or implements Enum.
+// Otherwise, it returns false.
+func EnumValues(t reflect.Type) ([]string, bool) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.String {
+		return nil, false
+	}
+
+	if values, ok := enumValues[t]; ok {
+		return values, true
+	} else if t.Implements(enumType) {
+		return reflect.Zero(t).Interface().(Enum).Values(), true
+	} else if reflect.PtrTo(t).Implements(enumType) {
+		return reflect.New(t).Interface().(Enum).Values(), true
+	}
+
+	return nil, false
+}
+
+// validEnumValue returns an error if t is an enum type and the (non-empty) value isn't one of its values.
+func validEnumValue(t reflect.Type, value string) gomerr.Gomerr {
+	values, ok := EnumValues(t)
+	if !ok || value == "" {
+		return nil
+	}
+
+	for _, v := range values {
+		if v == value {
+			return nil
+		}
+	}
+
+	return gomerr.InvalidValue(t.Name(), value, values).WithReason("Not one of the type's values")
+}
@@ -3,8 +3,6 @@ package flect
 import (
 	"reflect"
 	"strconv"
-	"strings"
-	"time"
 
 	"github.com/jt0/gomer/gomerr"
 )
@@ -29,12 +27,19 @@ func SetValue(targetValue reflect.Value, value interface{}) gomerr.Gomerr {
 	}
 
 	if stringValue, ok := value.(string); ok {
+		if ge := validEnumValue(indirectTargetValueType, stringValue); ge != nil {
+			return ge
+		}
 		if typedValue, ge := StringToType(stringValue, indirectTargetValueType); ge != nil {
 			return ge
 		} else if typedValue != nil {
 			value = typedValue
 		}
-	} // Feature:p2 handle []byte input and perform conversion?
+	} else if converted, ok, ge := Convert(value, indirectTargetValueType); ge != nil {
+		return ge
+	} else if ok {
+		value = converted
+	}
 
 	valueValue, ok := value.(reflect.Value)
 	if !ok {
@@ -96,10 +101,38 @@ func SetValue(targetValue reflect.Value, value interface{}) gomerr.Gomerr {
 	return nil
 }
 
+// SetValues sets a slice or array targetValue to the values, converting each one to the element type as with SetValue.
+// A slice is replaced by one with the same length as values, while an array must have at least as many elements.
+func SetValues(targetValue reflect.Value, values []string) gomerr.Gomerr {
+	switch targetValue.Kind() {
+	case reflect.Slice:
+		targetValue.Set(reflect.MakeSlice(targetValue.Type(), len(values), len(values)))
+	case reflect.Array:
+		if len(values) > targetValue.Len() {
+			return gomerr.Unprocessable("Too many values for array of length "+strconv.Itoa(targetValue.Len()), values)
+		}
+	default:
+		return gomerr.Unprocessable("Unable to set multiple values to '"+targetValue.Type().String()+"'", values)
+	}
+
+	for i, value := range values {
+		if ge := SetValue(targetValue.Index(i), value); ge != nil {
+			return ge.AddAttribute("Index", i)
+		}
+	}
+
+	return nil
+}
+
 // StringToType returns a value corresponding to the provided targetType. If the targetType isn't recognized, this
 // returns nil rather than an error. An error occurs if the targetType is recognized, but it's not possible to convert
-// the string into that type.
+// the string into that type. Registered converters (see RegisterConverter) take precedence over the built-in
+// conversions.
 func StringToType(valueString string, targetType reflect.Type) (interface{}, gomerr.Gomerr) {
+	if converted, ok, ge := Convert(valueString, targetType); ok || ge != nil {
+		return converted, ge
+	}
+
 	var value interface{}
 	var err error
 
@@ -179,17 +212,10 @@ func StringToType(valueString string, targetType reflect.Type) (interface{}, gom
 		}
 	case reflect.Float64:
 		value, err = strconv.ParseFloat(valueString, 64)
-	case reflect.Struct:
-		if targetType == timeType {
-			if strings.Index(valueString, "T") == -1 {
-				valueString = valueString + "T00:00:00Z"
-			}
-			value, err = time.Parse(time.RFC3339Nano, valueString)
-		}
 	case reflect.Slice:
 		if targetType == byteSliceType {
 			value = []byte(valueString) // NB: To decode the bytes, use (or define) a field function (e.g. $base64Decode)
-		} // Feature:p2 splitting comma separated values
+		} // NB: To set other slice types from multiple (or comma separated) values, use SetValues
 	}
 
 	if err != nil {
@@ -199,7 +225,4 @@ func StringToType(valueString string, targetType reflect.Type) (interface{}, gom
 	return value, nil
 }
 
-var (
-	timeType      = reflect.TypeOf((*time.Time)(nil)).Elem()
-	byteSliceType = reflect.TypeOf((*[]uint8)(nil)).Elem()
-)
+var byteSliceType = reflect.TypeOf((*[]uint8)(nil)).Elem()
@@ -9,6 +9,8 @@ const (
 	GenericBadValueType BadValueType = "BadValue"
 	InvalidValueType    BadValueType = "Invalid"
 	MalformedValueType  BadValueType = "Malformed"
+	ReadOnlyValueType   BadValueType = "ReadOnly"
+	UnknownValueType    BadValueType = "Unknown"
 
 	reasonAttributeKey   = "Reason"
 	expectedAttributeKey = "Expected"
@@ -33,6 +35,16 @@ func MalformedValue(name string, value interface{}) *BadValueError {
 	return Build(new(BadValueError), MalformedValueType, name, value).(*BadValueError)
 }
 
+// ReadOnlyValue indicates a value was provided for something that can't be written (e.g. by the current principal).
+func ReadOnlyValue(name string, value interface{}) *BadValueError {
+	return Build(new(BadValueError), ReadOnlyValueType, name, value).(*BadValueError)
+}
+
+// UnknownValue indicates a value was provided for something that isn't recognized (e.g. an unsupported attribute).
+func UnknownValue(name string, value interface{}) *BadValueError {
+	return Build(new(BadValueError), UnknownValueType, name, value).(*BadValueError)
+}
+
 func ValueExpired(name string, expiredAt time.Time) *BadValueError {
 	return Build(new(BadValueError), ExpiredValueType, name, expiredAt).(*BadValueError)
 }
@@ -0,0 +1,11 @@
+package gomerr
+
+type ForbiddenError struct {
+	Gomerr
+	Action string
+	Target interface{} `gomerr:"include_type"`
+}
+
+func Forbidden(action string, target interface{}) *ForbiddenError {
+	return Build(new(ForbiddenError), action, target).(*ForbiddenError)
+}
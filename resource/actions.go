@@ -5,6 +5,7 @@ import (
 	"reflect"
 
 	"github.com/jt0/gomer/auth"
+	"github.com/jt0/gomer/data"
 	"github.com/jt0/gomer/data/dataerr"
 	"github.com/jt0/gomer/gomerr"
 	"github.com/jt0/gomer/limit"
@@ -98,24 +99,26 @@ type OnReadFailer interface {
 }
 
 func ReadAction() Action {
-	return readAction{}
+	return &readAction{}
 }
 
-type readAction struct{}
+type readAction struct {
+	loaded bool
+}
 
-func (readAction) Name() string {
+func (*readAction) Name() string {
 	return "resource.ReadAction"
 }
 
-func (readAction) AppliesToCategory() Category {
+func (*readAction) AppliesToCategory() Category {
 	return InstanceCategory
 }
 
-func (readAction) FieldAccessPermissions() auth.AccessPermissions {
+func (*readAction) FieldAccessPermissions() auth.AccessPermissions {
 	return auth.ReadPermission
 }
 
-func (readAction) Pre(r Resource) gomerr.Gomerr {
+func (*readAction) Pre(r Resource) gomerr.Gomerr {
 	readable, ok := r.(Readable)
 	if !ok {
 		return gomerr.Unprocessable("Type does not implement resource.Readable", r)
@@ -124,15 +127,42 @@ func (readAction) Pre(r Resource) gomerr.Gomerr {
 	return readable.PreRead()
 }
 
-func (readAction) Do(r Resource) (ge gomerr.Gomerr) {
-	return r.metadata().dataStore.Read(r.(Readable))
+// authorizationTarget reads the stored instance so that policies are checked against it. If there isn't one and the
+// type creates a default on read, the policies are checked against the requested instance instead.
+func (a *readAction) authorizationTarget(r Resource) (Resource, gomerr.Gomerr) {
+	readable, ok := r.(Readable)
+	if !ok {
+		return nil, gomerr.Unprocessable("Type does not implement resource.Readable", r)
+	}
+
+	if ge := data.ReadFull(r.metadata().dataStore, readable); ge != nil {
+		if r.metadata().createsDefaultOnRead && errors.Is(ge, persistableNotFound) {
+			return r, nil
+		}
+		return nil, convertPersistableNotFoundIfApplicable(readable, ge)
+	}
+	a.loaded = true
+
+	return r, nil
+}
+
+func (a *readAction) Do(r Resource) (ge gomerr.Gomerr) {
+	if a.loaded {
+		return nil
+	}
+
+	if ge = r.metadata().dataStore.Read(r.(Readable)); ge != nil {
+		return createDefaultIfNotFound(r.(Readable), ge)
+	}
+
+	return nil
 }
 
-func (readAction) OnDoSuccess(r Resource) (Resource, gomerr.Gomerr) {
+func (*readAction) OnDoSuccess(r Resource) (Resource, gomerr.Gomerr) {
 	return r, r.(Readable).PostRead()
 }
 
-func (readAction) OnDoFailure(r Resource, ge gomerr.Gomerr) gomerr.Gomerr {
+func (*readAction) OnDoFailure(r Resource, ge gomerr.Gomerr) gomerr.Gomerr {
 	if failer, ok := r.(OnReadFailer); ok {
 		return failer.OnReadFailure(ge)
 	}
@@ -171,6 +201,25 @@ func (*updateAction) FieldAccessPermissions() auth.AccessPermissions {
 }
 
 func (a *updateAction) Pre(update Resource) gomerr.Gomerr {
+	if ge := a.loadActual(update); ge != nil {
+		return ge
+	}
+
+	if precondition := preconditionOf(update.(Updatable)); precondition != nil {
+		if ge := precondition(a.actual); ge != nil {
+			return ge
+		}
+	}
+
+	return a.actual.PreUpdate(update)
+}
+
+// loadActual reads the current (stored) instance that the update will be applied to, unless it's already been read.
+func (a *updateAction) loadActual(update Resource) gomerr.Gomerr {
+	if a.actual != nil {
+		return nil
+	}
+
 	r, ge := New(reflect.TypeOf(update), update.Subject())
 	if ge != nil {
 		return ge
@@ -186,14 +235,36 @@ func (a *updateAction) Pre(update Resource) gomerr.Gomerr {
 		return ge
 	}
 
+	// A singleton may not have its own 'id' fields, so IdTool wouldn't have copied anything
+	if current.metadata().singleton {
+		idfa, ge := idFieldsFor(reflect.ValueOf(current).Elem())
+		if ge != nil {
+			return ge
+		}
+		if ge = copyFields(idfa.idFields, reflect.ValueOf(update).Elem(), reflect.ValueOf(current).Elem()); ge != nil {
+			return ge
+		}
+	}
+
 	// Populate other fields with data from the underlying store
-	if ge = current.metadata().dataStore.Read(current); ge != nil {
+	if ge = data.ReadFull(current.metadata().dataStore, current); ge != nil {
 		return ge
 	}
 
+	current.setParent(update.Parent())
 	a.actual = current
 
-	return current.PreUpdate(update)
+	return nil
+}
+
+// authorizationTarget returns the current (stored) instance so that policies are checked against it rather than the
+// one containing the requested changes.
+func (a *updateAction) authorizationTarget(update Resource) (Resource, gomerr.Gomerr) {
+	if ge := a.loadActual(update); ge != nil {
+		return nil, ge
+	}
+
+	return a.actual, nil
 }
 
 func (a *updateAction) Do(update Resource) (ge gomerr.Gomerr) {
@@ -228,6 +299,7 @@ func DeleteAction() Action {
 
 type deleteAction struct {
 	limiter limit.Limiter
+	loaded  bool
 }
 
 func (*deleteAction) Name() string {
@@ -239,19 +311,62 @@ func (*deleteAction) AppliesToCategory() Category {
 }
 
 func (*deleteAction) FieldAccessPermissions() auth.AccessPermissions {
-	return auth.NoPermissions
+	return auth.DeletePermission
 }
 
-func (*deleteAction) Pre(r Resource) gomerr.Gomerr {
+func (a *deleteAction) Pre(r Resource) gomerr.Gomerr {
 	deletable, ok := r.(Deletable)
 	if !ok {
 		return gomerr.Unprocessable("Type does not implement resource.Deletable", r)
 	}
 
+	// A conditional delete reads the stored instance to check it against (which, for a data.Versioned one, also has the
+	// store verify it's unchanged when it's deleted)
+	if precondition := preconditionOf(deletable); precondition != nil {
+		if ge := a.load(deletable); ge != nil {
+			return ge
+		}
+		if ge := precondition(deletable); ge != nil {
+			return ge
+		}
+	}
+
 	return deletable.PreDelete()
 }
 
+// authorizationTarget reads the stored instance into the one being deleted so that policies are checked against it.
+func (a *deleteAction) authorizationTarget(r Resource) (Resource, gomerr.Gomerr) {
+	deletable, ok := r.(Deletable)
+	if !ok {
+		return nil, gomerr.Unprocessable("Type does not implement resource.Deletable", r)
+	}
+
+	if ge := a.load(deletable); ge != nil {
+		return nil, ge
+	}
+
+	return r, nil
+}
+
+// load reads the stored instance into the one being deleted, unless it's already been read.
+func (a *deleteAction) load(deletable Deletable) gomerr.Gomerr {
+	if a.loaded {
+		return nil
+	}
+
+	if ge := data.ReadFull(deletable.metadata().dataStore, deletable); ge != nil {
+		return convertPersistableNotFoundIfApplicable(deletable, ge)
+	}
+	a.loaded = true
+
+	return nil
+}
+
 func (a *deleteAction) Do(r Resource) (ge gomerr.Gomerr) {
+	if ge = applyChildDeletePolicies(r.(Deletable)); ge != nil {
+		return ge
+	}
+
 	a.limiter, ge = applyLimitAction(decrement, r)
 	if ge != nil {
 		return ge
@@ -326,6 +441,7 @@ func (listAction) Do(r Resource) gomerr.Gomerr {
 		item.setSelf(item)
 		item.setMetadata(r.metadata())
 		item.setSubject(r.Subject())
+		item.setParent(r.Parent())
 
 		if collectible, ok := item.(Collectible); ok {
 			if ge := collectible.OnCollect(r); ge != nil {
@@ -0,0 +1,190 @@
+package resource
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/jt0/gomer/auth"
+	"github.com/jt0/gomer/data"
+	"github.com/jt0/gomer/gomerr"
+	"github.com/jt0/gomer/id"
+)
+
+// AuditRecord describes an action performed by DoAction. For an update, Changes contains the before and after values
+// of each changed field. Values of sensitive fields (see auth.SensitiveFields) are replaced with Redacted.
+type AuditRecord struct {
+	RecordId     string
+	Time         time.Time
+	Principals   map[auth.PrincipalType][]string
+	Action       string
+	ResourceType string
+	ResourceId   string
+	Changes      map[string]AuditChange
+	Outcome      AuditOutcome
+	ErrorType    string
+}
+
+type AuditChange struct {
+	Before interface{}
+	After  interface{}
+}
+
+type AuditOutcome string
+
+const (
+	Succeeded AuditOutcome = "Succeeded"
+	Failed    AuditOutcome = "Failed"
+
+	Redacted = "[REDACTED]"
+)
+
+func (r *AuditRecord) TypeName() string {
+	return "AuditRecord"
+}
+
+func (r *AuditRecord) NewQueryable() data.Queryable {
+	return nil
+}
+
+func (r *AuditRecord) Id() string {
+	return r.RecordId
+}
+
+type AuditSink interface {
+	Record(record *AuditRecord) gomerr.Gomerr
+}
+
+type AuditSinkFunc func(record *AuditRecord) gomerr.Gomerr
+
+func (f AuditSinkFunc) Record(record *AuditRecord) gomerr.Gomerr {
+	return f(record)
+}
+
+var (
+	auditSink      AuditSink
+	onAuditFailure func(*AuditRecord, gomerr.Gomerr)
+)
+
+// SetAuditSink enables auditing of every action performed by DoAction (other than for types registered with
+// NotAudited). Auditing doesn't change the result of an action, so if the sink returns an error, onFailure (if not
+// nil) is called with the record and the error. Passing a nil sink disables auditing.
+func SetAuditSink(sink AuditSink, onFailure func(*AuditRecord, gomerr.Gomerr)) {
+	auditSink = sink
+	onAuditFailure = onFailure
+}
+
+// NotAudited is a Register option that excludes a resource type's actions from auditing.
+func NotAudited(md *metadata) {
+	md.notAudited = true
+}
+
+// StoreAuditSink returns an AuditSink that persists each record (with a generated RecordId) to the store.
+func StoreAuditSink(store data.Store) AuditSink {
+	var mu sync.Mutex
+	idGenerator := id.NewBase36IdGenerator(16, id.Chars)
+
+	return AuditSinkFunc(func(record *AuditRecord) gomerr.Gomerr {
+		mu.Lock()
+		record.RecordId = idGenerator.Generate()
+		mu.Unlock()
+
+		return store.Create(record)
+	})
+}
+
+type audit struct {
+	record *AuditRecord
+	before reflect.Value
+}
+
+func startAudit(r Resource, action Action) *audit {
+	if auditSink == nil || r.metadata().notAudited {
+		return nil
+	}
+
+	principals := make(map[auth.PrincipalType][]string)
+	for _, principalType := range []auth.PrincipalType{auth.Account, auth.Role, auth.User, auth.Group, auth.Request, auth.NoFieldAccess.Type()} {
+		for _, principal := range auth.PrincipalsOf(r.Subject(), principalType) {
+			principals[principalType] = append(principals[principalType], principal.Id())
+		}
+	}
+
+	return &audit{record: &AuditRecord{
+		Time:         time.Now(),
+		Principals:   principals,
+		Action:       action.Name(),
+		ResourceType: r.metadata().instanceName,
+	}}
+}
+
+// captureBefore keeps a copy of an update's current (stored) instance so that changes can be determined afterwards.
+func (a *audit) captureBefore(r Resource, action Action) {
+	if a == nil {
+		return
+	}
+
+	if update, ok := action.(*updateAction); ok && update.actual != nil {
+		current := reflect.ValueOf(update.actual).Elem()
+		a.before = reflect.New(current.Type()).Elem()
+		a.before.Set(current)
+	}
+}
+
+func (a *audit) finish(r Resource, result Resource, ge gomerr.Gomerr) {
+	if a == nil {
+		return
+	}
+
+	if result == nil {
+		result = r
+	}
+
+	if i, ok := result.(Instance); ok {
+		a.record.ResourceId = i.Id()
+	}
+
+	if ge != nil {
+		a.record.Outcome = Failed
+		a.record.ErrorType = reflect.TypeOf(ge).String()
+	} else {
+		a.record.Outcome = Succeeded
+		if a.before.IsValid() {
+			a.record.Changes = changes(a.before, reflect.ValueOf(result).Elem(), result)
+		}
+	}
+
+	if sinkGe := auditSink.Record(a.record); sinkGe != nil && onAuditFailure != nil {
+		onAuditFailure(a.record, sinkGe)
+	}
+}
+
+func changes(before, after reflect.Value, r Resource) map[string]AuditChange {
+	sensitive, ge := auth.SensitiveFields(r)
+	changed := make(map[string]AuditChange)
+	addChanges(changed, before, after, func(field string) bool { return ge != nil || sensitive[field] })
+
+	return changed
+}
+
+func addChanges(changed map[string]AuditChange, before, after reflect.Value, redact func(string) bool) {
+	for i := 0; i < before.NumField(); i++ {
+		sf := before.Type().Field(i)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			addChanges(changed, before.Field(i), after.Field(i), redact)
+			continue
+		} else if sf.PkgPath != "" { // unexported
+			continue
+		}
+
+		b, a := before.Field(i).Interface(), after.Field(i).Interface()
+		if reflect.DeepEqual(b, a) {
+			continue
+		}
+
+		if redact(sf.Name) {
+			b, a = Redacted, Redacted
+		}
+		changed[sf.Name] = AuditChange{Before: b, After: a}
+	}
+}
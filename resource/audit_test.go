@@ -0,0 +1,64 @@
+package resource_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jt0/gomer/_test/assert"
+	"github.com/jt0/gomer/auth"
+	"github.com/jt0/gomer/gomerr"
+	"github.com/jt0/gomer/resource"
+)
+
+type Account struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	AccountId string `id:"+"`
+	Name      string `access:"rw"`
+	Secret    string `access:"-w"`
+}
+
+func TestAuditRecordsUpdateChanges(t *testing.T) {
+	store.Clear()
+
+	auth.RegisterFieldAccessPrincipals(auth.NewFieldAccessPrincipal("admin"))
+	t.Cleanup(func() { auth.RegisterFieldAccessPrincipals() }) // no other test in this package registers principals
+
+	var records []*resource.AuditRecord
+	resource.SetAuditSink(resource.AuditSinkFunc(func(record *resource.AuditRecord) gomerr.Gomerr {
+		records = append(records, record)
+		return nil
+	}), nil)
+	defer resource.SetAuditSink(nil, nil)
+
+	actions := map[interface{}]func() resource.Action{"create": resource.CreateAction, "read": resource.ReadAction, "update": resource.UpdateAction}
+	_, ge := resource.Register(&Account{}, nil, actions, store, nil)
+	assert.Success(t, ge)
+	accountType := reflect.TypeOf(&Account{})
+
+	r, _ := resource.New(accountType, subject)
+	*r.(*Account) = Account{BaseInstance: r.(*Account).BaseInstance, AccountId: "a1", Name: "Before", Secret: "s1"}
+	_, ge = r.DoAction(resource.CreateAction())
+	assert.Success(t, ge)
+
+	r, _ = resource.New(accountType, subject)
+	*r.(*Account) = Account{BaseInstance: r.(*Account).BaseInstance, AccountId: "a1", Name: "After", Secret: "s2"}
+	_, ge = r.DoAction(resource.UpdateAction())
+	assert.Success(t, ge)
+
+	r, _ = resource.New(accountType, subject)
+	r.(*Account).AccountId = "missing"
+	_, ge = r.DoAction(resource.ReadAction())
+	assert.ErrorType(t, ge, &gomerr.NotFoundError{}, "Expected missing account")
+
+	assert.Equals(t, 3, len(records))
+	update := records[1]
+	assert.Equals(t, "resource.UpdateAction", update.Action)
+	assert.Equals(t, "a1", update.ResourceId)
+	assert.Equals(t, resource.Succeeded, update.Outcome)
+	assert.Equals(t, []string{auth.ReadWriteAll}, update.Principals[auth.ReadWriteAllFields.Type()])
+	assert.Equals(t, resource.AuditChange{Before: "Before", After: "After"}, update.Changes["Name"])
+	assert.Equals(t, resource.AuditChange{Before: resource.Redacted, After: resource.Redacted}, update.Changes["Secret"])
+	assert.Equals(t, resource.Failed, records[2].Outcome)
+	assert.Equals(t, "*gomerr.NotFoundError", records[2].ErrorType)
+}
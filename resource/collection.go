@@ -1,6 +1,9 @@
 package resource
 
 import (
+	"reflect"
+
+	"github.com/jt0/gomer/auth"
 	"github.com/jt0/gomer/data"
 	"github.com/jt0/gomer/gomerr"
 )
@@ -10,6 +13,15 @@ type Collection interface {
 	data.Queryable
 }
 
+func newCollection(md *metadata, subject auth.Subject) Collection {
+	collection := reflect.New(md.collectionType.Elem()).Interface().(Collection)
+	collection.setSelf(collection)
+	collection.setMetadata(md)
+	collection.setSubject(subject)
+
+	return collection
+}
+
 type BaseCollection struct {
 	BaseResource
 }
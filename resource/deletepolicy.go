@@ -0,0 +1,236 @@
+package resource
+
+import (
+	"reflect"
+
+	"github.com/jt0/gomer/data"
+	"github.com/jt0/gomer/gomerr"
+)
+
+// DeletePolicy determines what happens to a child resource's instances when their parent is deleted via
+// DeleteAction.
+type DeletePolicy int
+
+const (
+	// OrphanChildren leaves child instances in place. This is the default.
+	OrphanChildren DeletePolicy = iota
+	// RestrictDelete fails the parent's deletion with a gomerr.ConflictError if any child instances exist.
+	RestrictDelete
+	// CascadeDelete deletes each child instance (and, according to their own policies, their children) before the
+	// parent is deleted.
+	CascadeDelete
+	// DetachChildren clears the parent id fields on each child instance and saves it with an UpdateAction, so the
+	// change is authorized, its PreUpdate and PostUpdate hooks are called, and it's audited. A child's parent id fields
+	// cannot be part of its own id, and since a detached child has no parent to verify, the child must be registered
+	// with SkipsParentVerification.
+	DetachChildren
+)
+
+// OnParentDelete is a Register option that sets the DeletePolicy for a child resource type. Any policy other than
+// OrphanChildren requires the child to be registered with both a parent and a Collection type since the Collection is
+// used to find the child instances for a given parent.
+func OnParentDelete(policy DeletePolicy) func(*metadata) {
+	return func(md *metadata) {
+		md.deletePolicy = policy
+	}
+}
+
+func (m *metadata) validateDeletePolicy() gomerr.Gomerr {
+	if m.deletePolicy == OrphanChildren {
+		return nil
+	}
+
+	if m.parent == nil || m.collectionType == nil {
+		return gomerr.Configuration("A DeletePolicy requires the resource to have both a parent and a Collection type").AddAttribute("Resource", m.instanceName)
+	}
+
+	if m.deletePolicy == DetachChildren && m.parentCheck != skipParentCheck {
+		return gomerr.Configuration("DetachChildren requires the resource to be registered with SkipsParentVerification").AddAttribute("Resource", m.instanceName)
+	}
+
+	return nil
+}
+
+// applyChildDeletePolicies finds the instances of each child type of the given parent that has a DeletePolicy and
+// handles them accordingly.
+func applyChildDeletePolicies(parent Instance) gomerr.Gomerr {
+	for _, child := range parent.metadata().children {
+		childMd := child.(*metadata)
+
+		var applyPolicy func(*metadata, Instance, []Instance) gomerr.Gomerr
+		switch childMd.deletePolicy {
+		case RestrictDelete:
+			applyPolicy = restrict
+		case CascadeDelete:
+			applyPolicy = cascade
+		case DetachChildren:
+			applyPolicy = detach
+		default:
+			continue
+		}
+
+		if ge := forEachChildPage(childMd, parent, applyPolicy); ge != nil {
+			return ge
+		}
+	}
+
+	return nil
+}
+
+func forEachChildPage(childMd *metadata, parent Instance, fn func(*metadata, Instance, []Instance) gomerr.Gomerr) gomerr.Gomerr {
+	idFields, ge := idFieldsFor(reflect.ValueOf(parent).Elem())
+	if ge != nil {
+		return ge
+	}
+
+	collection := newCollection(childMd, parent.Subject())
+	if ge = copyFields(idFields.idFields, reflect.ValueOf(parent).Elem(), reflect.ValueOf(collection).Elem()); ge != nil {
+		return ge
+	}
+
+	for {
+		if ge = childMd.dataStore.Query(collection); ge != nil {
+			return ge
+		}
+
+		items := collection.Items()
+		children := make([]Instance, len(items))
+		for i, item := range items {
+			child := item.(Instance)
+			child.setSelf(child)
+			child.setMetadata(childMd)
+			child.setSubject(parent.Subject())
+			child.setParent(parent)
+			children[i] = child
+		}
+
+		if len(children) > 0 {
+			if ge = fn(childMd, parent, children); ge != nil {
+				return ge
+			}
+		}
+
+		if collection.NextPageToken() == nil {
+			return nil
+		}
+	}
+}
+
+func restrict(childMd *metadata, parent Instance, _ []Instance) gomerr.Gomerr {
+	return gomerr.Conflict(childMd.instanceName, "Cannot delete a resource that has child resources").WithSource(parent.metadata().instanceName + ":" + parent.Id())
+}
+
+// cascade deletes the page of children as a batch if the child's data store supports it. Otherwise, each child is
+// deleted with its own DoAction. A batched child is authorized and audited just as it would be by DoAction: its
+// PreDelete hook and delete policies are applied before, and its PostDelete hook after, the batch is deleted.
+func cascade(childMd *metadata, _ Instance, children []Instance) gomerr.Gomerr {
+	batchDeleter, canBatch := childMd.dataStore.(data.BatchDeleter)
+	if !canBatch {
+		for _, child := range children {
+			if _, ge := child.DoAction(DeleteAction()); ge != nil {
+				return ge
+			}
+		}
+		return nil
+	}
+
+	actions := make([]*deleteAction, 0, len(children))
+	audits := make([]*audit, 0, len(children))
+	persistables := make([]data.Persistable, 0, len(children))
+	for _, child := range children {
+		action := &deleteAction{loaded: true} // the child was just read by the query
+		actions = append(actions, action)
+		audits = append(audits, startAudit(child, action))
+
+		if ge := prepareBatchedDelete(child, action); ge != nil {
+			finishAudits(audits, children, ge)
+			return ge
+		}
+
+		persistables = append(persistables, child)
+	}
+
+	if ge := batchDeleter.BatchDelete(persistables); ge != nil {
+		finishAudits(audits, children, ge)
+		return ge
+	}
+
+	var errors []gomerr.Gomerr
+	for i, child := range children {
+		_, ge := actions[i].OnDoSuccess(child)
+		audits[i].finish(child, nil, ge)
+		if ge != nil {
+			errors = append(errors, ge)
+		}
+	}
+
+	return gomerr.Batcher(errors)
+}
+
+// prepareBatchedDelete performs the steps DoAction would for the child up to the point it's deleted.
+func prepareBatchedDelete(child Instance, action *deleteAction) (ge gomerr.Gomerr) {
+	if ge = authorize(child, action); ge != nil {
+		return ge
+	}
+
+	if ge = action.Pre(child); ge != nil {
+		return ge
+	}
+
+	if ge = applyChildDeletePolicies(child); ge != nil {
+		return ge
+	}
+
+	action.limiter, ge = applyLimitAction(decrement, child)
+	return ge
+}
+
+// finishAudits records the failure of each of the started audits, since none of their children were deleted.
+func finishAudits(audits []*audit, children []Instance, ge gomerr.Gomerr) {
+	for i, a := range audits {
+		a.finish(children[i], nil, ge)
+	}
+}
+
+func detach(childMd *metadata, parent Instance, children []Instance) gomerr.Gomerr {
+	parentIdFields, ge := idFieldsFor(reflect.ValueOf(parent).Elem())
+	if ge != nil {
+		return ge
+	}
+
+	childIdFields, ge := idFieldsFor(reflect.ValueOf(children[0]).Elem())
+	if ge != nil {
+		return ge
+	}
+
+	for _, parentIdField := range parentIdFields.idFields {
+		for _, childIdField := range childIdFields.idFields {
+			if parentIdField == childIdField {
+				return gomerr.Configuration("Cannot detach a child whose id includes its parent's id").AddAttributes("Resource", childMd.instanceName, "Field", parentIdField)
+			}
+		}
+	}
+
+	removed := make(map[string]bool, len(parentIdFields.idFields))
+	for _, idField := range parentIdFields.idFields {
+		removed[idField] = true
+	}
+
+	for _, child := range children {
+		cv := reflect.ValueOf(child).Elem()
+		for idField := range removed {
+			fv := cv.FieldByName(idField)
+			fv.Set(reflect.Zero(fv.Type()))
+		}
+		child.setParent(nil)
+
+		if ge = SetUpdateChanges(child, &data.Changes{Removed: removed}); ge != nil {
+			return ge
+		}
+		if _, ge = child.DoAction(UpdateAction()); ge != nil {
+			return ge
+		}
+	}
+
+	return nil
+}
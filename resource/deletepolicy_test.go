@@ -0,0 +1,341 @@
+package resource_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jt0/gomer/_test/assert"
+	"github.com/jt0/gomer/_test/helpers/stores"
+	"github.com/jt0/gomer/data"
+	"github.com/jt0/gomer/gomerr"
+	"github.com/jt0/gomer/resource"
+)
+
+type Folder struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	FolderId string `id:"+"`
+}
+
+type Doc struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	FolderId string
+	DocId    string `id:"+,FolderId"`
+}
+
+type Docs struct {
+	resource.BaseCollection `structs:"ignore"`
+	data.BaseQueryable      `structs:"ignore"`
+
+	FolderId string
+}
+
+func (Docs) MaximumPageSize() int {
+	return 0
+}
+
+type Album struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	AlbumId string `id:"+"`
+}
+
+type Photo struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	AlbumId string
+	PhotoId string `id:"+,AlbumId"`
+}
+
+type Photos struct {
+	resource.BaseCollection `structs:"ignore"`
+	data.BaseQueryable      `structs:"ignore"`
+
+	AlbumId string
+}
+
+func (Photos) MaximumPageSize() int {
+	return 0
+}
+
+type Shelf struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	ShelfId string `id:"+"`
+}
+
+type Book struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	ShelfId string
+	BookId  string `id:"+,ShelfId"`
+	Pinned  bool
+}
+
+type Books struct {
+	resource.BaseCollection `structs:"ignore"`
+	data.BaseQueryable      `structs:"ignore"`
+
+	ShelfId string
+}
+
+func (Books) MaximumPageSize() int {
+	return 0
+}
+
+type Team struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	TeamId string `id:"+"`
+}
+
+type Member struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	MemberId string `id:"+"`
+	TeamId   string
+}
+
+type Members struct {
+	resource.BaseCollection `structs:"ignore"`
+	data.BaseQueryable      `structs:"ignore"`
+
+	TeamId string
+}
+
+func (Members) MaximumPageSize() int {
+	return 0
+}
+
+// Guest is like Member, but is registered without SkipsParentVerification
+type Guest struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	GuestId string `id:"+"`
+	TeamId  string
+}
+
+type Guests struct {
+	resource.BaseCollection `structs:"ignore"`
+	data.BaseQueryable      `structs:"ignore"`
+
+	TeamId string
+}
+
+func (Guests) MaximumPageSize() int {
+	return 0
+}
+
+var (
+	lifecycle  = map[interface{}]func() resource.Action{"create": resource.CreateAction, "read": resource.ReadAction, "delete": resource.DeleteAction}
+	batchStore = stores.NewBatchMemoryStore()
+)
+
+func TestDeletePolicyRequiresCollection(t *testing.T) {
+	type Orphan struct {
+		resource.BaseInstance `structs:"ignore"`
+		OrphanId              string `id:"+"`
+	}
+
+	_, ge := resource.Register(&Orphan{}, nil, lifecycle, store, userMd, resource.OnParentDelete(resource.CascadeDelete))
+	assert.ErrorType(t, ge, &gomerr.ConfigurationError{}, "Cascade without a Collection type should fail")
+}
+
+func TestCascadeDelete(t *testing.T) {
+	store.Clear()
+
+	folderMd, ge := resource.Register(&Folder{}, nil, lifecycle, store, nil)
+	assert.Success(t, ge)
+	_, ge = resource.Register(&Doc{}, &Docs{}, lifecycle, store, folderMd, resource.OnParentDelete(resource.CascadeDelete))
+	assert.Success(t, ge)
+
+	folder := create(t, &Folder{FolderId: "f1"}).(*Folder)
+	create(t, &Doc{FolderId: "f1", DocId: "d1"})
+	create(t, &Doc{FolderId: "f1", DocId: "d2"})
+
+	_, ge = folder.DoAction(resource.DeleteAction())
+	assert.Success(t, ge)
+
+	for _, docId := range []string{"d1", "d2"} {
+		_, ge = newWith(t, &Doc{FolderId: "f1", DocId: docId}).DoAction(resource.ReadAction())
+		assert.ErrorType(t, ge, &gomerr.NotFoundError{}, "Child should have been deleted")
+	}
+}
+
+func TestBatchedCascadeDeleteIsAuthorizedAndAudited(t *testing.T) {
+	batchStore.Clear()
+
+	var records []*resource.AuditRecord
+	resource.SetAuditSink(resource.AuditSinkFunc(func(record *resource.AuditRecord) gomerr.Gomerr {
+		records = append(records, record)
+		return nil
+	}), nil)
+	defer resource.SetAuditSink(nil, nil)
+
+	shelfMd, ge := resource.Register(&Shelf{}, nil, lifecycle, batchStore, nil)
+	assert.Success(t, ge)
+	unpinned := resource.ForActions(resource.Allow(func(r resource.Resource, _ resource.Action) bool { return !r.(*Book).Pinned }), "resource.DeleteAction")
+	_, ge = resource.Register(&Book{}, &Books{}, lifecycle, batchStore, shelfMd, resource.OnParentDelete(resource.CascadeDelete), resource.AuthorizedBy(unpinned))
+	assert.Success(t, ge)
+
+	shelf := create(t, &Shelf{ShelfId: "s1"}).(*Shelf)
+	create(t, &Book{ShelfId: "s1", BookId: "b1"})
+	create(t, &Book{ShelfId: "s1", BookId: "b2"})
+	pinnedShelf := create(t, &Shelf{ShelfId: "s2"}).(*Shelf)
+	create(t, &Book{ShelfId: "s2", BookId: "b3", Pinned: true})
+
+	records = nil
+	_, ge = shelf.DoAction(resource.DeleteAction())
+	assert.Success(t, ge)
+
+	deleted := make(map[string]resource.AuditOutcome)
+	for _, record := range records {
+		if record.ResourceType == "Book" && record.Action == "resource.DeleteAction" {
+			deleted[record.ResourceId] = record.Outcome
+		}
+	}
+	assert.Equals(t, map[string]resource.AuditOutcome{"b1": resource.Succeeded, "b2": resource.Succeeded}, deleted)
+
+	_, ge = pinnedShelf.DoAction(resource.DeleteAction())
+	assert.ErrorType(t, ge, &gomerr.ForbiddenError{}, "Expected the pinned book's deletion to be forbidden")
+
+	_, ge = newWith(t, &Book{ShelfId: "s2", BookId: "b3"}).DoAction(resource.ReadAction())
+	assert.Success(t, ge)
+}
+
+func TestDetachChildren(t *testing.T) {
+	store.Clear()
+
+	teamMd, ge := resource.Register(&Team{}, nil, lifecycle, store, nil)
+	assert.Success(t, ge)
+
+	_, ge = resource.Register(&Guest{}, &Guests{}, lifecycle, store, teamMd, resource.OnParentDelete(resource.DetachChildren))
+	assert.ErrorType(t, ge, &gomerr.ConfigurationError{}, "Expected DetachChildren to require SkipsParentVerification")
+
+	_, ge = resource.Register(&Member{}, &Members{}, lifecycle, store, teamMd, resource.OnParentDelete(resource.DetachChildren), resource.SkipsParentVerification)
+	assert.Success(t, ge)
+
+	team := create(t, &Team{TeamId: "t1"}).(*Team)
+	create(t, &Member{MemberId: "m1", TeamId: "t1"})
+
+	_, ge = team.DoAction(resource.DeleteAction())
+	assert.Success(t, ge)
+
+	member, ge := newWith(t, &Member{MemberId: "m1"}).DoAction(resource.ReadAction())
+	assert.Success(t, ge)
+	assert.Equals(t, "", member.(*Member).TeamId)
+
+	_, ge = newWith(t, &Member{MemberId: "m1"}).DoAction(resource.DeleteAction())
+	assert.Success(t, ge)
+}
+
+type Project struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	ProjectId string `id:"+"`
+}
+
+type Task struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	TaskId    string `id:"+"`
+	ProjectId string
+	Locked    bool
+}
+
+type Tasks struct {
+	resource.BaseCollection `structs:"ignore"`
+	data.BaseQueryable      `structs:"ignore"`
+
+	ProjectId string
+}
+
+func (Tasks) MaximumPageSize() int {
+	return 0
+}
+
+func TestDetachChildrenIsAuthorizedAndAudited(t *testing.T) {
+	store.Clear()
+
+	var records []*resource.AuditRecord
+	resource.SetAuditSink(resource.AuditSinkFunc(func(record *resource.AuditRecord) gomerr.Gomerr {
+		records = append(records, record)
+		return nil
+	}), nil)
+	defer resource.SetAuditSink(nil, nil)
+
+	projectMd, ge := resource.Register(&Project{}, nil, lifecycle, store, nil)
+	assert.Success(t, ge)
+
+	unlocked := resource.ForActions(resource.Allow(func(r resource.Resource, _ resource.Action) bool { return !r.(*Task).Locked }), "resource.UpdateAction")
+	_, ge = resource.Register(&Task{}, &Tasks{}, lifecycle, store, projectMd, resource.OnParentDelete(resource.DetachChildren), resource.SkipsParentVerification, resource.AuthorizedBy(unlocked))
+	assert.Success(t, ge)
+
+	project := create(t, &Project{ProjectId: "p1"}).(*Project)
+	create(t, &Task{TaskId: "t1", ProjectId: "p1"})
+	lockedProject := create(t, &Project{ProjectId: "p2"}).(*Project)
+	create(t, &Task{TaskId: "t2", ProjectId: "p2", Locked: true})
+
+	_, ge = project.DoAction(resource.DeleteAction())
+	assert.Success(t, ge)
+
+	var detached bool
+	for _, record := range records {
+		detached = detached || record.ResourceType == "Task" && record.Action == "resource.UpdateAction" && record.Outcome == resource.Succeeded
+	}
+	assert.Assert(t, detached, "Expected the task's detachment to be audited")
+
+	_, ge = lockedProject.DoAction(resource.DeleteAction())
+	assert.ErrorType(t, ge, &gomerr.ForbiddenError{}, "Expected the locked task's detachment to be forbidden")
+
+	task, ge := newWith(t, &Task{TaskId: "t2"}).DoAction(resource.ReadAction())
+	assert.Success(t, ge)
+	assert.Equals(t, "p2", task.(*Task).ProjectId)
+
+	_, ge = newWith(t, &Project{ProjectId: "p2"}).DoAction(resource.ReadAction())
+	assert.Success(t, ge)
+}
+
+func TestRestrictDelete(t *testing.T) {
+	store.Clear()
+
+	albumMd, ge := resource.Register(&Album{}, nil, lifecycle, store, nil)
+	assert.Success(t, ge)
+	_, ge = resource.Register(&Photo{}, &Photos{}, lifecycle, store, albumMd, resource.OnParentDelete(resource.RestrictDelete))
+	assert.Success(t, ge)
+
+	album := create(t, &Album{AlbumId: "a1"}).(*Album)
+	create(t, &Photo{AlbumId: "a1", PhotoId: "p1"})
+
+	_, ge = album.DoAction(resource.DeleteAction())
+	assert.ErrorType(t, ge, &gomerr.ConflictError{}, "Delete with children should be restricted")
+
+	_, ge = newWith(t, &Photo{AlbumId: "a1", PhotoId: "p1"}).DoAction(resource.DeleteAction())
+	assert.Success(t, ge)
+
+	_, ge = album.DoAction(resource.DeleteAction())
+	assert.Success(t, ge)
+}
+
+// newWith returns a new resource of the same type as values with the values' exported fields copied in.
+func newWith(t *testing.T, values resource.Instance) resource.Instance {
+	r, ge := resource.New(reflect.TypeOf(values), subject)
+	assert.Success(t, ge)
+
+	rv, vv := reflect.ValueOf(r).Elem(), reflect.ValueOf(values).Elem()
+	for i := 0; i < vv.NumField(); i++ {
+		if !vv.Type().Field(i).Anonymous {
+			rv.Field(i).Set(vv.Field(i))
+		}
+	}
+
+	return r.(resource.Instance)
+}
+
+func create(t *testing.T, values resource.Instance) resource.Instance {
+	i := newWith(t, values)
+	_, ge := i.DoAction(resource.CreateAction())
+	assert.Success(t, ge)
+	return i
+}
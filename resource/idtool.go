@@ -113,15 +113,9 @@ func (a copyIdsApplier) Apply(sv reflect.Value, _ reflect.Value, tc *structs.Too
 }
 
 func Id(sv reflect.Value) (string, gomerr.Gomerr) {
-	idfa, ok := structIdFields[sv.Type().String()]
-	if !ok {
-		// TODO: dummy call to just prepare type is kinda...yeah. Maybe need a "Prepare" or something after all.
-		_ = structs.ApplyTools(sv, nil, DefaultIdFieldTool)
-
-		idfa, ok = structIdFields[sv.Type().String()]
-		if !ok {
-			return "", gomerr.Unprocessable("Unprocessed type or no field marked as an 'id'", sv.Type().String())
-		}
+	idfa, ge := idFieldsFor(sv)
+	if ge != nil {
+		return "", ge
 	}
 
 	fv := sv.FieldByName(idfa.idFields[0])
@@ -142,3 +136,20 @@ func Id(sv reflect.Value) (string, gomerr.Gomerr) {
 		return "", gomerr.Unprocessable("Id value does not provide a string representation", t)
 	}
 }
+
+func idFieldsFor(sv reflect.Value) (*copyIdsApplier, gomerr.Gomerr) {
+	idfa, ok := structIdFields[sv.Type().String()]
+	if !ok {
+		// TODO: dummy call to just prepare type is kinda...yeah. Maybe need a "Prepare" or something after all.
+		_ = structs.ApplyTools(sv, nil, DefaultIdFieldTool)
+
+		idfa, ok = structIdFields[sv.Type().String()]
+		if !ok {
+			if idfa, ok = singletonIdFields(sv); !ok {
+				return nil, gomerr.Unprocessable("Unprocessed type or no field marked as an 'id'", sv.Type().String())
+			}
+		}
+	}
+
+	return idfa, nil
+}
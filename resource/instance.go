@@ -29,25 +29,101 @@ func SaveInstance(i Instance) gomerr.Gomerr {
 type BaseInstance struct {
 	BaseResource
 
+	updateChanges      *data.Changes
+	precondition       Precondition
+	selectedAttributes []string
 	// persistedValues map[string]interface{}
 }
 
+// UpdateChanges returns the fields changed by an explicit update (see data.ExplicitUpdate and SetUpdateChanges), or
+// nil if the instance's zero values should be treated as unchanged.
+func (i *BaseInstance) UpdateChanges() *data.Changes {
+	return i.updateChanges
+}
+
+func (i *BaseInstance) setUpdateChanges(changes *data.Changes) {
+	i.updateChanges = changes
+}
+
+// SetUpdateChanges marks the update as explicitly changing the given fields, e.g. when it's bound from a JSON merge
+// patch. The changes are passed along to the data store (see data.ExplicitUpdate) and can be refined (e.g. by the
+// access tool) before then. The update must embed BaseInstance.
+func SetUpdateChanges(update Resource, changes *data.Changes) gomerr.Gomerr {
+	u, ok := update.(interface{ setUpdateChanges(*data.Changes) })
+	if !ok {
+		return gomerr.Unprocessable("Explicit updates require a type that embeds resource.BaseInstance", update)
+	}
+
+	u.setUpdateChanges(changes)
+	return nil
+}
+
+// Precondition is checked against the stored version of an instance before it's updated or deleted, e.g. to verify
+// that it hasn't changed since the client read it. See SetPrecondition.
+type Precondition func(current Instance) gomerr.Gomerr
+
+func (i *BaseInstance) setPrecondition(precondition Precondition) {
+	i.precondition = precondition
+}
+
+func (i *BaseInstance) getPrecondition() Precondition {
+	return i.precondition
+}
+
+// SetPrecondition has UpdateAction and DeleteAction check the precondition against the stored instance before it's
+// changed. If the check fails, its error is returned and the action isn't performed. The instance must embed
+// BaseInstance.
+func SetPrecondition(i Instance, precondition Precondition) gomerr.Gomerr {
+	pi, ok := i.(interface{ setPrecondition(Precondition) })
+	if !ok {
+		return gomerr.Unprocessable("Preconditions require a type that embeds resource.BaseInstance", i)
+	}
+
+	pi.setPrecondition(precondition)
+	return nil
+}
+
+// preconditionOf returns the precondition set on the instance, or nil if there isn't one.
+func preconditionOf(i Instance) Precondition {
+	if pi, ok := i.(interface{ getPrecondition() Precondition }); ok {
+		return pi.getPrecondition()
+	}
+	return nil
+}
+
+// SelectedAttributes returns the fields that a read of the instance is limited to (see data.AttributeSelector and
+// SelectAttributes), or nil if all of them are read.
+func (i *BaseInstance) SelectedAttributes() []string {
+	return i.selectedAttributes
+}
+
+func (i *BaseInstance) selectAttributes(fields []string) {
+	i.selectedAttributes = fields
+}
+
+// SelectAttributes limits the fields read from the data store when the instance is read, e.g. to those a client asked
+// to be returned. Reads that precede a change to the instance (such as an update's) ignore the selection so that the
+// fields that weren't selected aren't lost (see data.ReadFull). The instance must embed BaseInstance.
+func SelectAttributes(i Instance, fields []string) gomerr.Gomerr {
+	si, ok := i.(interface{ selectAttributes([]string) })
+	if !ok {
+		return gomerr.Unprocessable("Attribute selection requires a type that embeds resource.BaseInstance", i)
+	}
+
+	si.selectAttributes(fields)
+	return nil
+}
+
 func (i BaseInstance) TypeName() string {
 	return i.md.instanceName
 }
 
 func (i BaseInstance) NewQueryable() data.Queryable {
-	ct := i.metadata().collectionType
-	if ct == nil {
+	if i.metadata().collectionType == nil {
 		return nil
 	}
 
-	collection := reflect.New(ct.Elem()).Interface().(Collection)
-	collection.setSelf(collection)
-	collection.setMetadata(i.md)
-	collection.setSubject(i.Subject())
-
-	return collection
+	return newCollection(i.md, i.Subject())
 }
 
 func (i BaseInstance) Id() string {
@@ -0,0 +1,101 @@
+package resource_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jt0/gomer/_test/assert"
+	"github.com/jt0/gomer/data"
+	"github.com/jt0/gomer/data/dataerr"
+	"github.com/jt0/gomer/gomerr"
+	"github.com/jt0/gomer/resource"
+)
+
+type Contact struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	ContactId string `id:"+"`
+	Name      string
+	Email     string
+}
+
+// SelectedAttributes always limits reads to the contact's name
+func (*Contact) SelectedAttributes() []string {
+	return []string{"Name"}
+}
+
+type Subscription struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	SubscriptionId string `id:"+"`
+	Name           string
+	Plan           string
+}
+
+var crud = map[interface{}]func() resource.Action{"create": resource.CreateAction, "read": resource.ReadAction, "update": resource.UpdateAction, "delete": resource.DeleteAction}
+
+func TestSelectAttributesLimitsRead(t *testing.T) {
+	store.Clear()
+
+	_, ge := resource.Register(&Subscription{}, nil, crud, store, nil)
+	assert.Success(t, ge)
+
+	r, ge := resource.New(reflect.TypeOf(&Subscription{}), subject)
+	assert.Success(t, ge)
+	subscription := r.(*Subscription)
+	subscription.SubscriptionId, subscription.Name, subscription.Plan = "s1", "Acme", "gold"
+	_, ge = subscription.DoAction(resource.CreateAction())
+	assert.Success(t, ge)
+
+	r, ge = resource.New(reflect.TypeOf(&Subscription{}), subject)
+	assert.Success(t, ge)
+	read := r.(*Subscription)
+	read.SubscriptionId = "s1"
+	assert.Success(t, resource.SelectAttributes(read, []string{"Name"}))
+	_, ge = read.DoAction(resource.ReadAction())
+	assert.Success(t, ge)
+	assert.Equals(t, Subscription{SubscriptionId: "s1", Name: "Acme"}, Subscription{SubscriptionId: read.SubscriptionId, Name: read.Name, Plan: read.Plan})
+}
+
+func TestWritesIgnoreAttributeSelection(t *testing.T) {
+	store.Clear()
+
+	_, ge := resource.Register(&Contact{}, nil, crud, store, nil)
+	assert.Success(t, ge)
+
+	newContact := func(name string) *Contact {
+		r, ge := resource.New(reflect.TypeOf(&Contact{}), subject)
+		assert.Success(t, ge)
+		contact := r.(*Contact)
+		contact.ContactId, contact.Name = "c1", name
+		return contact
+	}
+
+	contact := newContact("Ann")
+	contact.Email = "ann@example.com"
+	_, ge = contact.DoAction(resource.CreateAction())
+	assert.Success(t, ge)
+
+	read := newContact("")
+	_, ge = read.DoAction(resource.ReadAction())
+	assert.Success(t, ge)
+	assert.Equals(t, "", read.Email) // only the selected attributes were read
+
+	_, ge = newContact("Anne").DoAction(resource.UpdateAction())
+	assert.Success(t, ge)
+
+	stored := newContact("")
+	assert.Success(t, store.ReadFull(stored))
+	assert.Equals(t, Contact{ContactId: "c1", Name: "Anne", Email: "ann@example.com"}, Contact{ContactId: stored.ContactId, Name: stored.Name, Email: stored.Email})
+
+	deleted := newContact("")
+	assert.Success(t, resource.SetPrecondition(deleted, func(current resource.Instance) gomerr.Gomerr {
+		if current.(*Contact).Email == "" {
+			return gomerr.Internal("Expected the precondition to be checked against the whole contact")
+		}
+		return nil
+	}))
+	_, ge = deleted.DoAction(resource.DeleteAction())
+	assert.Success(t, ge)
+	assert.ErrorType(t, data.ReadFull(store, newContact("")), &dataerr.PersistableNotFoundError{}, "Expected the contact to be deleted")
+}
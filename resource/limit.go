@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 
+	"github.com/jt0/gomer/data"
 	"github.com/jt0/gomer/gomerr"
 	"github.com/jt0/gomer/limit"
 )
@@ -72,7 +73,7 @@ func applyLimitAction(limitAction limitAction, i Resource) (limit.Limiter, gomer
 		li.setSubject(i.Subject())
 
 		// TODO: cache in case needed by more than one resource...
-		if ge = li.metadata().dataStore.Read(li); ge != nil {
+		if ge = data.ReadFull(li.metadata().dataStore, li); ge != nil {
 			return nil, ge
 		}
 
@@ -11,18 +11,26 @@ import (
 type Metadata interface {
 	ResourceType(Category) reflect.Type
 	Actions() map[interface{}]func() Action
-	// Parent() Metadata
+	Parent() Metadata
 	Children() []Metadata
 }
 
-func Register(instance Instance, collection Collection, actions map[interface{}]func() Action, dataStore data.Store, parentMetadata Metadata) (md *metadata, ge gomerr.Gomerr) {
+// Register creates the Metadata for the given Instance (and, if not nil, Collection) type. If parentMetadata is
+// provided, the new resource is treated as a child of it: actions against the child will first verify that the parent
+// instance identified by the child's id fields exists. Options can be used to alter this and other behaviors.
+func Register(instance Instance, collection Collection, actions map[interface{}]func() Action, dataStore data.Store, parentMetadata Metadata, options ...func(*metadata)) (md *metadata, ge gomerr.Gomerr) {
 	if instance == nil {
 		return nil, gomerr.Configuration("Must register with an Instance type")
 	}
 
 	it := reflect.TypeOf(instance)
+	nilSafeParentMetadata, _ := parentMetadata.(*metadata)
+
 	md, _ = resourceTypeToMetadata[it]
 	if md != nil {
+		if md.collectionType != reflect.TypeOf(collection) || md.parent != nilSafeParentMetadata {
+			return nil, gomerr.Configuration("Resource type is already registered with a different collection type or parent").AddAttribute("Type", it.String())
+		}
 		return md, nil
 	}
 
@@ -41,8 +49,6 @@ func Register(instance Instance, collection Collection, actions map[interface{}]
 		unqualifiedCollectionName = unqualifiedCollectionName[strings.Index(unqualifiedCollectionName, ".")+1:]
 	}
 
-	nilSafeParentMetadata, _ := parentMetadata.(*metadata)
-
 	md = &metadata{
 		instanceType:   it,
 		instanceName:   unqualifiedInstanceName,
@@ -52,6 +58,15 @@ func Register(instance Instance, collection Collection, actions map[interface{}]
 		dataStore:      dataStore,
 		parent:         nilSafeParentMetadata,
 		children:       make([]Metadata, 0),
+		parentCheck:    verifyParent,
+	}
+
+	for _, option := range options {
+		option(md)
+	}
+
+	if ge = gomerr.Batch(md.validateDeletePolicy(), md.validateSingleton()); ge != nil {
+		return nil, ge
 	}
 
 	if nilSafeParentMetadata != nil {
@@ -69,14 +84,20 @@ func Register(instance Instance, collection Collection, actions map[interface{}]
 var resourceTypeToMetadata = make(map[reflect.Type]*metadata)
 
 type metadata struct {
-	instanceType   reflect.Type
-	instanceName   string
-	collectionType reflect.Type
-	collectionName string
-	actions        map[interface{}]func() Action
-	dataStore      data.Store
-	parent         *metadata
-	children       []Metadata // Using interface type since we aren't currently using child attributes
+	instanceType         reflect.Type
+	instanceName         string
+	collectionType       reflect.Type
+	collectionName       string
+	actions              map[interface{}]func() Action
+	dataStore            data.Store
+	parent               *metadata
+	children             []Metadata // Using interface type since we aren't currently using child attributes
+	parentCheck          parentCheck
+	deletePolicy         DeletePolicy
+	singleton            bool
+	createsDefaultOnRead bool
+	policy               Policy
+	notAudited           bool
 
 	// idFields       []field
 }
@@ -96,13 +117,13 @@ func (m *metadata) Actions() map[interface{}]func() Action {
 	return m.actions
 }
 
-// func (m *metadata) Parent() Metadata {
-// 	if m.parent == nil {
-// 		return nil
-// 	}
-//
-// 	return m.parent
-// }
+func (m *metadata) Parent() Metadata {
+	if m.parent == nil {
+		return nil // avoids returning a non-nil interface holding a nil *metadata
+	}
+
+	return m.parent
+}
 
 func (m *metadata) Children() []Metadata {
 	return m.children
@@ -0,0 +1,99 @@
+package resource
+
+import (
+	"reflect"
+
+	"github.com/jt0/gomer/data"
+	"github.com/jt0/gomer/flect"
+	"github.com/jt0/gomer/gomerr"
+)
+
+type parentCheck int
+
+const (
+	verifyParent parentCheck = iota
+	skipParentCheck
+)
+
+// VerifiesParent is a Register option that causes each action on a child resource to first read its parent instance.
+// If the parent doesn't exist, the action fails with a gomerr.NotFoundError. The loaded parent is available to the
+// child's hooks via Resource.Parent(). This is the default behavior for resources registered with a parent.
+func VerifiesParent(md *metadata) {
+	md.parentCheck = verifyParent
+}
+
+// SkipsParentVerification is a Register option that disables the parent existence check. Resource.Parent() will
+// return nil for resources registered with this option.
+func SkipsParentVerification(md *metadata) {
+	md.parentCheck = skipParentCheck
+}
+
+// loadParent reads the parent instance of r (if r's type has one) using the values in r's fields that share a name
+// with the parent's id fields. The parent's ancestors are verified first. If the parent is Readable, the subject must
+// also be authorized to read it, but since the parent is read directly from its data store, the read isn't audited
+// and the parent's read hooks aren't called.
+func loadParent(r Resource) gomerr.Gomerr {
+	md := r.metadata()
+	if md == nil || md.parent == nil || md.parentCheck == skipParentCheck || r.Parent() != nil {
+		return nil
+	}
+
+	pr, ge := New(md.parent.instanceType, r.Subject())
+	if ge != nil {
+		return ge
+	}
+	parent := pr.(Instance) // Register() only accepts an Instance for the instance type
+
+	if ge = copyParentIds(parent, r); ge != nil {
+		return ge
+	}
+
+	if md.parent.dataStore == nil {
+		return gomerr.Configuration("Parent resource type has no data store: " + md.parent.instanceName)
+	}
+
+	if ge = loadParent(parent); ge != nil {
+		return ge
+	}
+
+	if _, ok := parent.(Readable); ok {
+		if ge = authorize(parent, ReadAction()); ge != nil {
+			return ge
+		}
+	}
+
+	if ge = data.ReadFull(md.parent.dataStore, parent); ge != nil {
+		return convertPersistableNotFoundIfApplicable(parent, ge)
+	}
+
+	r.setParent(parent)
+
+	return nil
+}
+
+func copyParentIds(parent Instance, child Resource) gomerr.Gomerr {
+	pv := reflect.ValueOf(parent).Elem()
+	idfa, ge := idFieldsFor(pv)
+	if ge != nil {
+		return ge
+	}
+
+	return copyFields(idfa.idFields, reflect.ValueOf(child).Elem(), pv)
+}
+
+// copyFields sets each named field in 'to' with the value of the same-named field in 'from'.
+func copyFields(fieldNames []string, from, to reflect.Value) gomerr.Gomerr {
+	for _, fieldName := range fieldNames {
+		ffv := from.FieldByName(fieldName)
+		tfv := to.FieldByName(fieldName)
+		if !ffv.IsValid() || !tfv.IsValid() {
+			return gomerr.Configuration("Parent and child resources must both have a field for the parent's id").AddAttributes("From", from.Type().String(), "To", to.Type().String(), "Field", fieldName)
+		}
+
+		if ge := flect.SetValue(tfv, ffv.Interface()); ge != nil {
+			return ge.AddAttribute("Field", fieldName)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,231 @@
+package resource_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jt0/gomer/_test/assert"
+	"github.com/jt0/gomer/_test/helpers/stores"
+	"github.com/jt0/gomer/auth"
+	"github.com/jt0/gomer/data"
+	"github.com/jt0/gomer/data/dataerr"
+	"github.com/jt0/gomer/gomerr"
+	"github.com/jt0/gomer/resource"
+)
+
+type User struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	UserId string `id:"+"`
+	Name   string
+}
+
+type Post struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	UserId string
+	PostId string `id:"+,UserId"`
+	Title  string
+}
+
+// Resource types are registered once (a repeated Register returns the existing metadata), so each test that stores
+// instances clears the shared store first rather than using its own.
+var (
+	store     = stores.NewMemoryStore()
+	subject   = auth.NewSubject(auth.ReadWriteAllFields)
+	crudl     = map[interface{}]func() resource.Action{"create": resource.CreateAction, "read": resource.ReadAction}
+	userMd, _ = resource.Register(&User{}, nil, crudl, store, nil)
+	postMd, _ = resource.Register(&Post{}, nil, crudl, store, userMd)
+	userType  = reflect.TypeOf(&User{})
+	postType  = reflect.TypeOf(&Post{})
+)
+
+func TestParentMetadata(t *testing.T) {
+	assert.Equals(t, userMd, postMd.Parent())
+	assert.Equals(t, nil, userMd.Parent())
+	assert.Equals(t, postMd, userMd.Children()[0])
+}
+
+func TestChildActionFailsWithoutParent(t *testing.T) {
+	store.Clear()
+
+	r, ge := resource.New(postType, subject)
+	assert.Success(t, ge)
+	post := r.(*Post)
+	post.UserId, post.PostId = "nobody", "p1"
+
+	_, ge = post.DoAction(resource.CreateAction())
+	assert.ErrorType(t, ge, &gomerr.NotFoundError{}, "Expected parent to not be found")
+}
+
+func TestChildActionLoadsParent(t *testing.T) {
+	store.Clear()
+
+	r, ge := resource.New(userType, subject)
+	assert.Success(t, ge)
+	user := r.(*User)
+	user.UserId, user.Name = "u1", "Alice"
+	_, ge = user.DoAction(resource.CreateAction())
+	assert.Success(t, ge)
+
+	r, ge = resource.New(postType, subject)
+	assert.Success(t, ge)
+	post := r.(*Post)
+	post.UserId, post.PostId, post.Title = "u1", "p1", "Hello"
+
+	var records []*resource.AuditRecord
+	resource.SetAuditSink(resource.AuditSinkFunc(func(record *resource.AuditRecord) gomerr.Gomerr {
+		records = append(records, record)
+		return nil
+	}), nil)
+	defer resource.SetAuditSink(nil, nil)
+
+	_, ge = post.DoAction(resource.CreateAction())
+	assert.Success(t, ge)
+
+	parent, ok := post.Parent().(*User)
+	assert.Assert(t, ok, "Expected parent to be a *User")
+	assert.Equals(t, "Alice", parent.Name)
+
+	assert.Equals(t, 1, len(records)) // loading the parent isn't audited
+	assert.Equals(t, "Post", records[0].ResourceType)
+}
+
+type Settings struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	UserId  string
+	Theme   string
+	Created bool
+}
+
+func (s *Settings) SetDefaults() gomerr.Gomerr {
+	s.Theme = "light"
+	return nil
+}
+
+func (s *Settings) PreCreate() gomerr.Gomerr {
+	s.Created = true
+	return nil
+}
+
+func TestSingletonCreatesDefaultOnRead(t *testing.T) {
+	store.Clear()
+
+	var records []*resource.AuditRecord
+	resource.SetAuditSink(resource.AuditSinkFunc(func(record *resource.AuditRecord) gomerr.Gomerr {
+		records = append(records, record)
+		return nil
+	}), nil)
+	defer resource.SetAuditSink(nil, nil)
+
+	_, ge := resource.Register(&Settings{}, nil, crudl, store, userMd, resource.Singleton, resource.CreatesDefaultOnRead)
+	assert.Success(t, ge)
+
+	r, ge := resource.New(userType, subject)
+	assert.Success(t, ge)
+	user := r.(*User)
+	user.UserId = "u2"
+	_, ge = user.DoAction(resource.CreateAction())
+	assert.Success(t, ge)
+
+	r, ge = resource.New(reflect.TypeOf(&Settings{}), subject)
+	assert.Success(t, ge)
+	settings := r.(*Settings)
+	settings.UserId = "u2"
+
+	_, ge = settings.DoAction(resource.ReadAction())
+	assert.Success(t, ge)
+	assert.Equals(t, "light", settings.Theme)
+	assert.Equals(t, "u2", settings.Id())
+	assert.Assert(t, settings.Created, "Expected the default to be created with a CreateAction")
+
+	var created bool
+	for _, record := range records {
+		created = created || record.ResourceType == "Settings" && record.Action == "resource.CreateAction" && record.Outcome == resource.Succeeded
+	}
+	assert.Assert(t, created, "Expected the default's creation to be audited")
+}
+
+type Preferences struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	UserId   string
+	Language string
+}
+
+func (p *Preferences) SetDefaults() gomerr.Gomerr {
+	p.Language = "en"
+	return nil
+}
+
+// racingStore simulates another request creating the default Preferences between a request's read and its create.
+type racingStore struct {
+	stores.MemoryStore
+	raced *bool
+}
+
+func (s racingStore) Read(p data.Persistable) gomerr.Gomerr {
+	if preferences, ok := p.(*Preferences); ok && !*s.raced {
+		*s.raced = true
+		preferences.Language = "de"
+		ge := s.MemoryStore.Create(preferences)
+		preferences.Language = ""
+		if ge != nil {
+			return ge
+		}
+		return dataerr.PersistableNotFound(p.TypeName(), preferences.Id())
+	}
+	return s.MemoryStore.Read(p)
+}
+
+var racing = racingStore{MemoryStore: store, raced: new(bool)}
+
+func TestSingletonDefaultCreatedConcurrently(t *testing.T) {
+	store.Clear()
+	*racing.raced = false
+
+	_, ge := resource.Register(&Preferences{}, nil, crudl, racing, userMd, resource.Singleton, resource.CreatesDefaultOnRead)
+	assert.Success(t, ge)
+
+	r, ge := resource.New(userType, subject)
+	assert.Success(t, ge)
+	user := r.(*User)
+	user.UserId = "u3"
+	_, ge = user.DoAction(resource.CreateAction())
+	assert.Success(t, ge)
+
+	r, ge = resource.New(reflect.TypeOf(&Preferences{}), subject)
+	assert.Success(t, ge)
+	preferences := r.(*Preferences)
+	preferences.UserId = "u3"
+
+	_, ge = preferences.DoAction(resource.ReadAction())
+	assert.Success(t, ge)
+	assert.Equals(t, "de", preferences.Language) // the other request's default
+}
+
+type Workspace struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	Name string
+}
+
+func TestSingletonRequiresParent(t *testing.T) {
+	_, ge := resource.Register(&Workspace{}, nil, crudl, store, nil, resource.Singleton)
+	assert.ErrorType(t, ge, &gomerr.ConfigurationError{}, "Expected a singleton without a parent to be rejected")
+	assert.Equals(t, "A singleton must have a parent", ge.(*gomerr.ConfigurationError).Problem)
+}
+
+func TestDescribeAndValidate(t *testing.T) {
+	d, ge := resource.Describe(postMd)
+	assert.Success(t, ge)
+	assert.Equals(t, "*resource_test.User", d.Parent)
+	assert.Equals(t, []string{"PostId", "UserId"}, d.IdFields)
+	assert.Equals(t, "resource.CreateAction", d.Actions["create"])
+
+	_, ge = resource.Register(&Post{}, &Docs{}, crudl, store, userMd)
+	assert.ErrorType(t, ge, &gomerr.ConfigurationError{}, "Expected re-registration with a different collection to fail")
+
+	assert.Success(t, resource.Validate())
+}
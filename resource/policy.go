@@ -0,0 +1,187 @@
+package resource
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/jt0/gomer/auth"
+	"github.com/jt0/gomer/gomerr"
+)
+
+// Policy decides whether a resource's Subject may perform an action on it. Policies are evaluated by DoAction before
+// the action's Pre step (so hooks such as PreCreate don't run for a denied action). A Read, Update, or Delete is checked
+// against the stored instance, while a Create (or other action) is checked against the requested one. A Policy should
+// return a gomerr.ForbiddenError to deny the action.
+type Policy interface {
+	Authorize(r Resource, action Action) gomerr.Gomerr
+}
+
+type PolicyFunc func(r Resource, action Action) gomerr.Gomerr
+
+func (f PolicyFunc) Authorize(r Resource, action Action) gomerr.Gomerr {
+	return f(r, action)
+}
+
+// DefaultPolicy is used for resource types registered without an AuthorizedBy option. If nil, all actions are allowed.
+var DefaultPolicy Policy
+
+// AuthorizedBy is a Register option that specifies the policies that must all allow an action before it's performed.
+func AuthorizedBy(policies ...Policy) func(*metadata) {
+	return func(md *metadata) {
+		md.policy = AllOf(policies...)
+	}
+}
+
+// authorizationTargeter is implemented by actions that authorize against the stored instance rather than the one
+// DoAction was called on. For example, UpdateAction's policies are evaluated against the current instance rather than
+// the one containing the requested changes. The target is only read if the resource type has a policy.
+type authorizationTargeter interface {
+	authorizationTarget(r Resource) (Resource, gomerr.Gomerr)
+}
+
+// authorize verifies the subject has the type-level permissions the action requires (see auth.TypeAccessGranted)
+// and then evaluates the resource type's policy.
+func authorize(r Resource, action Action) gomerr.Gomerr {
+	if granted, ge := auth.TypeAccessGranted(r.Subject(), reflect.TypeOf(r), action.FieldAccessPermissions()); ge != nil {
+		return ge
+	} else if !granted {
+		return forbidden(r, action)
+	}
+
+	policy := r.metadata().policy
+	if policy == nil {
+		policy = DefaultPolicy
+	}
+
+	if policy == nil {
+		return nil
+	}
+
+	if targeter, ok := action.(authorizationTargeter); ok {
+		target, ge := targeter.authorizationTarget(r)
+		if ge != nil {
+			return ge
+		}
+		r = target
+	}
+
+	return policy.Authorize(r, action)
+}
+
+// AllOf returns a Policy that allows an action only if every one of the provided policies does. The first denial is
+// returned.
+func AllOf(policies ...Policy) Policy {
+	return PolicyFunc(func(r Resource, action Action) gomerr.Gomerr {
+		for _, policy := range policies {
+			if ge := policy.Authorize(r, action); ge != nil {
+				return ge
+			}
+		}
+		return nil
+	})
+}
+
+// AnyOf returns a Policy that allows an action if at least one of the provided policies does. If none do, the last
+// denial is returned.
+func AnyOf(policies ...Policy) Policy {
+	return PolicyFunc(func(r Resource, action Action) (ge gomerr.Gomerr) {
+		if len(policies) == 0 {
+			return forbidden(r, action)
+		}
+
+		for _, policy := range policies {
+			if ge = policy.Authorize(r, action); ge == nil {
+				return nil
+			}
+		}
+		return ge
+	})
+}
+
+// ForActions returns a Policy that applies the provided one only to actions with one of the given names (e.g.
+// "resource.DeleteAction"). Other actions are allowed.
+func ForActions(policy Policy, actionNames ...string) Policy {
+	names := make(map[string]bool, len(actionNames))
+	for _, name := range actionNames {
+		names[name] = true
+	}
+
+	return PolicyFunc(func(r Resource, action Action) gomerr.Gomerr {
+		if !names[action.Name()] {
+			return nil
+		}
+		return policy.Authorize(r, action)
+	})
+}
+
+// Allow returns a Policy that allows an action if the predicate returns true.
+func Allow(predicate func(r Resource, action Action) bool) Policy {
+	return PolicyFunc(func(r Resource, action Action) gomerr.Gomerr {
+		if !predicate(r, action) {
+			return forbidden(r, action)
+		}
+		return nil
+	})
+}
+
+// HasPrincipal returns a Policy that allows an action if the Subject has a principal of the given type. If ids are
+// provided, the id of one of the Subject's principals of that type must also be one of them.
+func HasPrincipal(principalType auth.PrincipalType, ids ...string) Policy {
+	return Allow(func(r Resource, _ Action) bool {
+		for _, principal := range auth.PrincipalsOf(r.Subject(), principalType) {
+			if len(ids) == 0 {
+				return true
+			}
+
+			for _, id := range ids {
+				if principal.Id() == id {
+					return true
+				}
+			}
+		}
+		return false
+	})
+}
+
+// OwnedBy returns a Policy that allows an action if the value of the resource's ownerField matches the id of one of
+// the Subject's principals of the given type. If the resource doesn't have the field but its parent does (e.g. a child
+// of a User resource), the parent's value is used. A Read, Update, or Delete is checked against the stored instance, and
+// a Create against the requested one (so the owner must be provided with the request rather than set by PreCreate).
+func OwnedBy(ownerField string, principalType auth.PrincipalType) Policy {
+	return Allow(func(r Resource, _ Action) bool {
+		owner, ok := fieldString(r, ownerField)
+		if !ok && r.Parent() != nil {
+			owner, ok = fieldString(r.Parent(), ownerField)
+		}
+		if !ok || owner == "" {
+			return false
+		}
+
+		for _, principal := range auth.PrincipalsOf(r.Subject(), principalType) {
+			if owner == principal.Id() {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func fieldString(r Resource, fieldName string) (string, bool) {
+	fv := reflect.ValueOf(r).Elem().FieldByName(fieldName)
+	if !fv.IsValid() {
+		return "", false
+	}
+
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return "", true
+		}
+		fv = fv.Elem()
+	}
+
+	return fmt.Sprint(fv.Interface()), true
+}
+
+func forbidden(r Resource, action Action) gomerr.Gomerr {
+	return gomerr.Forbidden(action.Name(), r)
+}
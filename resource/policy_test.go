@@ -0,0 +1,78 @@
+package resource_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jt0/gomer/_test/assert"
+	"github.com/jt0/gomer/auth"
+	"github.com/jt0/gomer/gomerr"
+	"github.com/jt0/gomer/resource"
+)
+
+type Note struct {
+	resource.BaseInstance `structs:"ignore"`
+
+	NoteId  string `id:"+"`
+	OwnerId string
+}
+
+// preparedDeletes counts the calls to PreDelete, which shouldn't be made for a denied delete
+var preparedDeletes int
+
+func (*Note) PreDelete() gomerr.Gomerr {
+	preparedDeletes++
+	return nil
+}
+
+type userPrincipal string
+
+func (u userPrincipal) Id() string               { return string(u) }
+func (userPrincipal) Type() auth.PrincipalType   { return auth.User }
+func (userPrincipal) Release(bool) gomerr.Gomerr { return nil }
+
+func TestOwnedByPolicy(t *testing.T) {
+	store.Clear()
+
+	actions := map[interface{}]func() resource.Action{"create": resource.CreateAction, "read": resource.ReadAction, "update": resource.UpdateAction, "delete": resource.DeleteAction}
+	_, ge := resource.Register(&Note{}, nil, actions, store, nil, resource.AuthorizedBy(resource.OwnedBy("OwnerId", auth.User)))
+	assert.Success(t, ge)
+
+	alice := auth.NewSubject(auth.ReadWriteAllFields, userPrincipal("alice"))
+	bob := auth.NewSubject(auth.ReadWriteAllFields, userPrincipal("bob"))
+	note := func(subject auth.Subject, noteId string, ownerId string) resource.Resource {
+		r, ge := resource.New(reflect.TypeOf(&Note{}), subject)
+		assert.Success(t, ge)
+		r.(*Note).NoteId, r.(*Note).OwnerId = noteId, ownerId
+		return r
+	}
+
+	_, ge = note(alice, "n1", "alice").DoAction(resource.CreateAction())
+	assert.Success(t, ge)
+
+	_, ge = note(bob, "n1", "bob").DoAction(resource.UpdateAction())
+	assert.ErrorType(t, ge, &gomerr.ForbiddenError{}, "Expected non-owner's update to be forbidden")
+
+	_, ge = note(bob, "n2", "alice").DoAction(resource.CreateAction())
+	assert.ErrorType(t, ge, &gomerr.ForbiddenError{}, "Expected creating another user's note to be forbidden")
+
+	// Reads and deletes are checked against the stored note, so the request needn't include its owner
+	read, ge := note(alice, "n1", "").DoAction(resource.ReadAction())
+	assert.Success(t, ge)
+	assert.Equals(t, "alice", read.(*Note).OwnerId)
+
+	_, ge = note(bob, "n1", "").DoAction(resource.ReadAction())
+	assert.ErrorType(t, ge, &gomerr.ForbiddenError{}, "Expected non-owner's read to be forbidden")
+
+	_, ge = note(bob, "n1", "bob").DoAction(resource.ReadAction())
+	assert.ErrorType(t, ge, &gomerr.ForbiddenError{}, "Expected non-owner's read to be forbidden even if it claims to be the owner")
+
+	prepared := preparedDeletes
+	_, ge = note(bob, "n1", "").DoAction(resource.DeleteAction())
+	assert.ErrorType(t, ge, &gomerr.ForbiddenError{}, "Expected non-owner's delete to be forbidden")
+	assert.Equals(t, prepared, preparedDeletes) // the denied delete wasn't prepared
+
+	_, ge = note(alice, "n1", "").DoAction(resource.DeleteAction())
+	assert.Success(t, ge)
+	assert.Equals(t, prepared+1, preparedDeletes)
+}
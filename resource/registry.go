@@ -0,0 +1,167 @@
+package resource
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/jt0/gomer/gomerr"
+	"github.com/jt0/gomer/structs"
+)
+
+// Registered returns the Metadata for each registered resource type, ordered by instance type name.
+func Registered() []Metadata {
+	registered := registeredMetadata()
+	mds := make([]Metadata, len(registered))
+	for i, md := range registered {
+		mds[i] = md
+	}
+
+	return mds
+}
+
+func registeredMetadata() []*metadata {
+	seen := make(map[*metadata]bool, len(resourceTypeToMetadata))
+	mds := make([]*metadata, 0, len(resourceTypeToMetadata))
+	for _, md := range resourceTypeToMetadata {
+		if !seen[md] {
+			seen[md] = true
+			mds = append(mds, md)
+		}
+	}
+
+	sort.Slice(mds, func(i, j int) bool {
+		return mds[i].instanceType.String() < mds[j].instanceType.String()
+	})
+
+	return mds
+}
+
+// Description summarizes how a resource type has been registered and configured. Bindings contains the non-empty
+// directive for each field (keyed by field name, then by tool type) of the tools provided to Describe.
+type Description struct {
+	Instance   string
+	Collection string
+	Parent     string
+	Singleton  bool
+	Actions    map[string]string // action key -> action name
+	DataStore  string
+	IdFields   []string
+	Bindings   map[string]map[string]string
+}
+
+func Describe(md Metadata, tools ...*structs.Tool) (Description, gomerr.Gomerr) {
+	m, ok := md.(*metadata)
+	if !ok || m == nil {
+		return Description{}, gomerr.Unprocessable("Not a registered resource's Metadata", md)
+	}
+
+	d := Description{
+		Instance:  m.instanceType.String(),
+		Singleton: m.singleton,
+		Actions:   make(map[string]string, len(m.actions)),
+		DataStore: fmt.Sprintf("%T", m.dataStore),
+		Bindings:  make(map[string]map[string]string),
+	}
+
+	if m.collectionType != nil {
+		d.Collection = m.collectionType.String()
+	}
+
+	if m.parent != nil {
+		d.Parent = m.parent.instanceType.String()
+	}
+
+	for key, action := range m.actions {
+		d.Actions[fmt.Sprint(key)] = action().Name()
+	}
+
+	idfa, ge := idFieldsFor(reflect.New(m.instanceType.Elem()).Elem())
+	if ge != nil {
+		return d, ge
+	}
+	d.IdFields = idfa.idFields
+
+	addBindings(d.Bindings, m.instanceType.Elem(), tools)
+
+	return d, nil
+}
+
+func addBindings(bindings map[string]map[string]string, st reflect.Type, tools []*structs.Tool) {
+	for i := 0; i < st.NumField(); i++ {
+		sf := st.Field(i)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			addBindings(bindings, sf.Type, tools)
+			continue
+		} else if sf.PkgPath != "" { // unexported
+			continue
+		}
+
+		for _, tool := range tools {
+			directive := tool.DirectiveProvider().Get(st, sf)
+			if directive == "" {
+				continue
+			}
+
+			if bindings[sf.Name] == nil {
+				bindings[sf.Name] = make(map[string]string)
+			}
+			bindings[sf.Name][tool.Type()] = directive
+		}
+	}
+}
+
+// Validate prepares the Instance and Collection types of every registered resource with IdTool and the provided
+// tools. Rather than fail on the first problem, it returns all configuration errors it finds (as a gomerr.BatchError
+// if there is more than one) so that they can be reported when an application starts instead of when a request
+// first uses the type.
+func Validate(tools ...*structs.Tool) gomerr.Gomerr {
+	tools = append([]*structs.Tool{IdTool}, tools...)
+
+	var errors []gomerr.Gomerr
+	for _, md := range registeredMetadata() {
+		for _, rt := range []reflect.Type{md.instanceType, md.collectionType} {
+			if rt == nil {
+				continue
+			}
+
+			if ge := structs.Preprocess(rt, tools...); ge != nil {
+				errors = append(errors, gomerr.Configuration("Invalid resource type").AddAttribute("Type", rt.String()).Wrap(ge))
+			}
+		}
+
+		if ge := md.validateParentIdFields(); ge != nil {
+			errors = append(errors, ge)
+		}
+	}
+
+	return gomerr.Batcher(errors)
+}
+
+// validateParentIdFields checks that the instance type (and, if applicable, the collection type) of a child resource
+// has fields for each of its parent's id fields.
+func (m *metadata) validateParentIdFields() gomerr.Gomerr {
+	if m.parent == nil || m.parentCheck == skipParentCheck && m.deletePolicy == OrphanChildren {
+		return nil
+	}
+
+	idfa, ge := idFieldsFor(reflect.New(m.parent.instanceType.Elem()).Elem())
+	if ge != nil {
+		return gomerr.Configuration("Unable to determine parent's id fields").AddAttribute("Type", m.parent.instanceType.String()).Wrap(ge)
+	}
+
+	var errors []gomerr.Gomerr
+	for _, rt := range []reflect.Type{m.instanceType, m.collectionType} {
+		if rt == nil {
+			continue
+		}
+
+		for _, idField := range idfa.idFields {
+			if _, ok := rt.Elem().FieldByName(idField); !ok {
+				errors = append(errors, gomerr.Configuration("Missing a field for the parent's id").AddAttributes("Type", rt.String(), "Field", idField))
+			}
+		}
+	}
+
+	return gomerr.Batcher(errors)
+}
@@ -10,12 +10,14 @@ import (
 type Resource interface {
 	Metadata() Metadata
 	Subject() auth.Subject
+	Parent() Instance
 	DoAction(Action) (Resource, gomerr.Gomerr)
 
 	setSelf(Resource)
 	metadata() *metadata
 	setMetadata(*metadata)
 	setSubject(auth.Subject)
+	setParent(Instance)
 }
 
 type Action interface {
@@ -50,9 +52,10 @@ func New(resourceType reflect.Type, subject auth.Subject) (Resource, gomerr.Gome
 }
 
 type BaseResource struct {
-	self Resource
-	md   *metadata
-	sub  auth.Subject
+	self   Resource
+	md     *metadata
+	sub    auth.Subject
+	parent Instance
 }
 
 func (b *BaseResource) Metadata() Metadata {
@@ -63,12 +66,33 @@ func (b *BaseResource) Subject() auth.Subject {
 	return b.sub
 }
 
-func (b *BaseResource) DoAction(action Action) (Resource, gomerr.Gomerr) {
-	if ge := action.Pre(b.self); ge != nil {
+// Parent returns the parent instance loaded (and so verified to exist) prior to performing an action. If the resource
+// type has no parent or was registered with SkipsParentVerification, this returns nil.
+func (b *BaseResource) Parent() Instance {
+	return b.parent
+}
+
+func (b *BaseResource) DoAction(action Action) (result Resource, ge gomerr.Gomerr) {
+	a := startAudit(b.self, action)
+	defer func() {
+		a.finish(b.self, result, ge)
+	}()
+
+	if ge = loadParent(b.self); ge != nil {
+		return nil, ge
+	}
+
+	if ge = authorize(b.self, action); ge != nil {
 		return nil, ge
 	}
 
-	if ge := action.Do(b.self); ge != nil {
+	if ge = action.Pre(b.self); ge != nil {
+		return nil, ge
+	}
+
+	a.captureBefore(b.self, action)
+
+	if ge = action.Do(b.self); ge != nil {
 		return nil, action.OnDoFailure(b.self, ge)
 	}
 
@@ -90,3 +114,7 @@ func (b *BaseResource) setMetadata(metadata *metadata) {
 func (b *BaseResource) setSubject(subject auth.Subject) {
 	b.sub = subject
 }
+
+func (b *BaseResource) setParent(parent Instance) {
+	b.parent = parent
+}
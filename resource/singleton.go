@@ -0,0 +1,94 @@
+package resource
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/jt0/gomer/gomerr"
+)
+
+// Singleton is a Register option for a child Instance type that has at most one instance per parent and so has no
+// Collection type. If the singleton's type has no field with an 'id' struct tag, its id fields are the same as its
+// parent's, which means it must have fields with the same names.
+func Singleton(md *metadata) {
+	md.singleton = true
+}
+
+// CreatesDefaultOnRead is a Register option for singletons. If a ReadAction finds no stored instance, a new one is
+// created (after calling SetDefaults() if the type is a Defaulter) and returned instead of a NotFound error. The new
+// instance is created with a CreateAction, so it's authorized, its PreCreate and PostCreate hooks are called, any
+// limits are applied, and it's audited just as if it had been created directly. If another request creates it first
+// (i.e. the create fails with a gomerr.ConflictError), the stored instance is read and returned instead.
+func CreatesDefaultOnRead(md *metadata) {
+	md.createsDefaultOnRead = true
+}
+
+// Defaulter is an optional interface for singletons registered with CreatesDefaultOnRead that can populate default
+// values for a new instance.
+type Defaulter interface {
+	SetDefaults() gomerr.Gomerr
+}
+
+// typeName -> metadata for singleton types
+var singletonTypes = make(map[string]*metadata)
+
+func (m *metadata) validateSingleton() gomerr.Gomerr {
+	if m.createsDefaultOnRead && !m.singleton {
+		return gomerr.Configuration("Only singletons can be registered with CreatesDefaultOnRead").AddAttribute("Resource", m.instanceName)
+	}
+
+	if !m.singleton {
+		return nil
+	}
+
+	if m.collectionType != nil {
+		return gomerr.Configuration("A singleton cannot have a Collection type").AddAttribute("Resource", m.instanceName)
+	}
+
+	if m.parent == nil {
+		return gomerr.Configuration("A singleton must have a parent").AddAttribute("Resource", m.instanceName)
+	}
+
+	singletonTypes[m.instanceType.Elem().String()] = m
+
+	return nil
+}
+
+// singletonIdFields returns the id fields of the parent type if sv is a singleton.
+func singletonIdFields(sv reflect.Value) (*copyIdsApplier, bool) {
+	md, ok := singletonTypes[sv.Type().String()]
+	if !ok {
+		return nil, false
+	}
+
+	parentIdFields, ge := idFieldsFor(reflect.New(md.parent.instanceType.Elem()).Elem())
+	if ge != nil {
+		return nil, false
+	}
+
+	structIdFields[sv.Type().String()] = parentIdFields
+
+	return parentIdFields, true
+}
+
+func createDefaultIfNotFound(r Readable, ge gomerr.Gomerr) gomerr.Gomerr {
+	if !r.metadata().createsDefaultOnRead || !errors.Is(ge, persistableNotFound) {
+		return ge
+	}
+
+	if defaulter, ok := r.(Defaulter); ok {
+		if ge = defaulter.SetDefaults(); ge != nil {
+			return ge
+		}
+	}
+
+	_, ge = r.DoAction(CreateAction())
+
+	// Another request created the default first
+	var conflict *gomerr.ConflictError
+	if errors.As(ge, &conflict) {
+		return r.metadata().dataStore.Read(r)
+	}
+
+	return ge
+}
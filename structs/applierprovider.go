@@ -40,7 +40,7 @@ func ScopeAlias(alias, scope string) {
 	}
 
 	if current, ok := scopeAliases[alias]; ok && current != scope {
-		panic(fmt.Sprintf("%s already aliased tp %s. First delete the existing alias to %s first.", alias, current, scope))
+		panic(fmt.Sprintf("%s already aliased to %s. First delete the existing alias to %s.", alias, current, scope))
 	}
 
 	scopeAliases[alias] = scope
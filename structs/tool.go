@@ -71,6 +71,10 @@ func (t *Tool) ApplierProvider() ApplierProvider {
 	return t.applierProvider
 }
 
+func (t *Tool) DirectiveProvider() DirectiveProvider {
+	return t.directiveProvider
+}
+
 func (t *Tool) applierFor(st reflect.Type, sf reflect.StructField) (Applier, gomerr.Gomerr) {
 	return applyScopes(t.applierProvider, st, sf, t.directiveProvider.Get(st, sf))
 }
@@ -94,14 +98,18 @@ func (s StructTagDirectiveProvider) Get(_ reflect.Type, structField reflect.Stru
 var (
 	idGen           = id.NewBase36IdGenerator(4, id.Chars)
 	preparedStructs = map[string]*preparedStruct{}
+	preparing       = map[string]*preparedStruct{} // structs being processed, so a self-referential one isn't repeated
 	timeType        = reflect.TypeOf((*time.Time)(nil)).Elem()
 )
 
 func process(st reflect.Type, tools ...*Tool) (*preparedStruct, []gomerr.Gomerr) {
-	for k := st.Kind(); k != reflect.Struct; k = st.Kind() {
+	for elemTypes := map[reflect.Type]bool{}; st.Kind() != reflect.Struct; st = st.Elem() {
 		switch st.Kind() {
 		case reflect.Array, reflect.Map, reflect.Ptr, reflect.Slice:
-			st = st.Elem()
+			if elemTypes[st] {
+				return nil, nil // a type that only contains itself (e.g. 'type L []L') has no struct
+			}
+			elemTypes[st] = true
 		default:
 			return nil, nil
 		}
@@ -114,6 +122,10 @@ func process(st reflect.Type, tools ...*Tool) (*preparedStruct, []gomerr.Gomerr)
 
 	var toolsForStruct []*Tool
 	typeName := st.String()
+	if ps, ok := preparing[typeName]; ok {
+		return ps, nil
+	}
+
 	ps, ok := preparedStructs[typeName]
 	if ok {
 		for _, tool := range tools {
@@ -134,6 +146,9 @@ func process(st reflect.Type, tools ...*Tool) (*preparedStruct, []gomerr.Gomerr)
 		}
 	}
 
+	preparing[typeName] = ps
+	defer delete(preparing, typeName)
+
 	errors := make([]gomerr.Gomerr, 0)
 	for i := 0; i < st.NumField(); i++ {
 		sf := st.Field(i)
@@ -142,6 +157,13 @@ func process(st reflect.Type, tools ...*Tool) (*preparedStruct, []gomerr.Gomerr)
 			continue
 		}
 
+		// Tools aren't applied to unexported fields (or their contents), so unless the field is embedded (and so its
+		// fields may be promoted), skip it. This also avoids following unexported references back to this type.
+		unexported := unicode.IsLower([]rune(sf.Name)[0])
+		if unexported && !sf.Anonymous {
+			continue
+		}
+
 		sft := sf.Type
 		switch sft.Kind() {
 		case reflect.Struct:
@@ -153,15 +175,12 @@ func process(st reflect.Type, tools ...*Tool) (*preparedStruct, []gomerr.Gomerr)
 				}
 			}
 		case reflect.Array, reflect.Map, reflect.Ptr, reflect.Slice:
-			if sft.String() == "*resource.metadata" {
-				println("Found", sft.String())
-			}
 			_, subErrors := process(sft.Elem(), tools...)
 			errors = append(errors, subErrors...)
 		}
 
 		// TODO: Is there a case where we want to interpret a directive on this attribute?
-		if unicode.IsLower([]rune(sf.Name)[0]) {
+		if unexported {
 			continue
 		}
 
@@ -172,12 +191,16 @@ func process(st reflect.Type, tools ...*Tool) (*preparedStruct, []gomerr.Gomerr)
 			} else if applier != nil {
 				appliers[tool.Id()] = applier
 			}
-			ps.applied[tool.Id()] = true
 		}
 		ps.addAppliers(sf.Name, appliers)
 	}
 
+	// A tool is only marked as applied if the struct was processed without error so that the errors are returned again
+	// (rather than the struct used as is) the next time the tool is applied.
 	if len(errors) == 0 {
+		for _, tool := range toolsForStruct {
+			ps.applied[tool.Id()] = true
+		}
 		preparedStructs[ps.typeName] = ps
 	}
 